@@ -14,6 +14,11 @@ const (
 	delimiter       = "."
 	configFilePerm  = os.FileMode(0o600)
 	defaultFileName = "famed-annotated.yml"
+
+	// DefaultThreatModelDir is the directory the threat model files are written to and read from by default.
+	DefaultThreatModelDir = "threatmodel"
+
+	defaultHistoryRetention = 30
 )
 
 // DefaultConfig returns a fully initialized(? maybe not the best word) configuration.
@@ -21,16 +26,27 @@ func NewDefault() error {
 	k := koanf.New(delimiter)
 
 	// Load defaults values
-	err := k.Load(confmap.Provider(map[string]interface{}{
+	if err := k.Load(confmap.Provider(defaults(), delimiter), nil); err != nil {
+		return eris.Wrap(err, "failed to load configuration from default values")
+	}
+
+	return write(k)
+}
+
+// defaults returns the default values of the configuration file.
+func defaults() map[string]interface{} {
+	return map[string]interface{}{
 		"project.name":        "famed-annotated",
 		"project.description": "A famed-annotated project.",
 		"imports":             []string{"./"},
 		"paths":               []string{"./"},
-	}, delimiter), nil)
-	if err != nil {
-		return eris.Wrap(err, "failed to load configuration from default values")
+		"threatmodel_dir":     DefaultThreatModelDir,
+		"history.retention":   defaultHistoryRetention,
 	}
+}
 
+// write writes the configuration file.
+func write(k *koanf.Koanf) error {
 	b, err := k.Marshal(yaml.Parser())
 	if err != nil {
 		return eris.Wrap(err, "failed to marshal configuration")
@@ -43,6 +59,62 @@ func NewDefault() error {
 	return nil
 }
 
+// ImportThreatspec sets the project, the imports and the paths of the configuration file, created with the default
+// values if missing, from the threatspec.yaml configuration file of a threatspec project. The paths threatspec
+// configures with ignore patterns are kept without them, and returned.
+func ImportThreatspec(filename string) ([]string, error) {
+	k := koanf.New(delimiter)
+	if err := k.Load(confmap.Provider(defaults(), delimiter), nil); err != nil {
+		return nil, eris.Wrap(err, "failed to load configuration from default values")
+	}
+	if _, err := os.Stat(defaultFileName); err == nil {
+		if err := k.Load(file.Provider(defaultFileName), yaml.Parser()); err != nil {
+			return nil, eris.Wrap(err, "failed to load yaml file")
+		}
+	}
+
+	ts := koanf.New(delimiter)
+	if err := ts.Load(file.Provider(filename), yaml.Parser()); err != nil {
+		return nil, eris.Wrapf(err, "failed to load %s", filename)
+	}
+
+	values := map[string]interface{}{}
+	for _, key := range []string{"project.name", "project.description"} {
+		if ts.String(key) != "" {
+			values[key] = ts.String(key)
+		}
+	}
+
+	var ignored []string
+	for _, key := range []string{"imports", "paths"} {
+		var paths []string
+		items, _ := ts.Get(key).([]interface{})
+		for _, item := range items {
+			switch item := item.(type) {
+			case string:
+				paths = append(paths, item)
+			case map[string]interface{}:
+				// A path with options, like ignore patterns.
+				if path, ok := item["path"].(string); ok {
+					paths = append(paths, path)
+					if _, ok := item["ignore"]; ok {
+						ignored = append(ignored, path)
+					}
+				}
+			}
+		}
+		if len(paths) > 0 {
+			values[key] = paths
+		}
+	}
+
+	if err := k.Load(confmap.Provider(values, delimiter), nil); err != nil {
+		return nil, eris.Wrapf(err, "failed to load %s", filename)
+	}
+
+	return ignored, write(k)
+}
+
 // LoadFile retrieves values from filePath configuration file.
 func LoadFile() (*Config, error) {
 	k := koanf.New(delimiter)
@@ -62,5 +134,12 @@ func LoadFile() (*Config, error) {
 		return nil, eris.Wrap(err, "failed to unmarshal config")
 	}
 
+	if config.ThreatModelDir == "" {
+		config.ThreatModelDir = DefaultThreatModelDir
+	}
+	if !k.Exists("history.retention") {
+		config.History.Retention = defaultHistoryRetention
+	}
+
 	return config, nil
 }
@@ -3,9 +3,25 @@ package config
 // Config is the complete representation of the configuration, it is authoritative on configuration names, hierarchy, structure and type.
 type Config struct {
 	Imports []string `koanf:"imports"`
+	// Paths are the directories searched for annotations, the current directory when empty.
+	Paths   []string `koanf:"paths"`
 	Project struct {
 		Name        string `koanf:"name"`
 		Description string `koanf:"description"`
 	} `koanf:"project"`
 	RepositoryURL string `koanf:"repository_url"`
+	// RepositoryURLPattern is github, gitlab, gitea, bitbucket or a pattern with the {repository}, {commit}, {file}
+	// and {line} placeholders, used to link annotations to the web UI of the repository.
+	RepositoryURLPattern string `koanf:"repository_url_pattern"`
+	// ThreatModelDir is the directory the threat model files are written to by run and read from by report.
+	ThreatModelDir string `koanf:"threatmodel_dir"`
+	Report         struct {
+		Templates []string `koanf:"templates"`
+	} `koanf:"report"`
+	// History configures the snapshots of the threat model archived by run.
+	History struct {
+		Disabled bool `koanf:"disabled"`
+		// Retention is the number of snapshots kept, all of them when 0.
+		Retention int `koanf:"retention"`
+	} `koanf:"history"`
 }
@@ -0,0 +1,161 @@
+package library
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// capecURL is the page of an attack pattern on the CAPEC website.
+const capecURL = "https://capec.mitre.org/data/definitions/%s.html"
+
+// Subset of the MITRE CAPEC XML catalogue, capec_vX.xml.
+type (
+	capecCatalog struct {
+		AttackPatterns []capecAttackPattern `xml:"Attack_Patterns>Attack_Pattern"`
+	}
+	capecAttackPattern struct {
+		ID                string      `xml:"ID,attr"`
+		Name              string      `xml:"Name,attr"`
+		Abstraction       string      `xml:"Abstraction,attr"`
+		Status            string      `xml:"Status,attr"`
+		Description       capecText   `xml:"Description"`
+		Likelihood        string      `xml:"Likelihood_Of_Attack"`
+		Severity          string      `xml:"Typical_Severity"`
+		RelatedWeaknesses []cweMember `xml:"Related_Weaknesses>Related_Weakness"`
+		Mitigations       []capecText `xml:"Mitigations>Mitigation"`
+	}
+	// capecText is the text of an element, which may be structured with XHTML elements.
+	capecText string
+)
+
+// UnmarshalXML collects the character data of the element and of its children, separated by spaces.
+func (t *capecText) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var text []string
+	for depth := 1; depth > 0; {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			text = append(text, string(token))
+		}
+	}
+	*t = capecText(collapse(strings.Join(text, " ")))
+
+	return nil
+}
+
+// ImportCAPEC adds the attack patterns of a MITRE CAPEC XML catalogue to the library as threats with #capec-63 like
+// ids, and returns how many were imported. The CWE weaknesses they exploit are kept as custom data with #cwe-79 like
+// ids, along with their mitigations as candidate controls, their likelihood, severity and page on the CAPEC website.
+// When linked is set, only the attack patterns exploiting a weakness of the library are imported. Deprecated attack
+// patterns are skipped.
+func (l *Library) ImportCAPEC(filename string, linked bool) (int, error) {
+	file, err := os.ReadFile(filename)
+	if err != nil {
+		return 0, eris.Wrapf(err, "failed to read %s", filename)
+	}
+
+	var catalog capecCatalog
+	if err := xml.Unmarshal(file, &catalog); err != nil {
+		return 0, eris.Wrapf(err, "failed to understand %s", filename)
+	}
+
+	imported := 0
+	for _, p := range catalog.AttackPatterns {
+		if p.Status == "Deprecated" {
+			continue
+		}
+
+		var weaknesses []interface{}
+		known := false
+		for _, w := range p.RelatedWeaknesses {
+			weaknesses = append(weaknesses, CWEID(w.CWEID))
+			if _, ok := l.Threats[CWEID(w.CWEID)]; ok {
+				known = true
+			}
+		}
+		if linked && !known {
+			continue
+		}
+
+		custom := Custom{
+			"kind":        KindAttackPattern,
+			"abstraction": p.Abstraction,
+			"url":         fmt.Sprintf(capecURL, p.ID),
+		}
+		if p.Likelihood != "" {
+			custom["likelihood"] = p.Likelihood
+		}
+		if p.Severity != "" {
+			custom["severity"] = p.Severity
+		}
+		if len(weaknesses) > 0 {
+			custom["weaknesses"] = weaknesses
+		}
+		var controls []interface{}
+		for _, m := range p.Mitigations {
+			if m != "" {
+				controls = append(controls, string(m))
+			}
+		}
+		if len(controls) > 0 {
+			custom["candidate_controls"] = controls
+		}
+
+		l.importThreat(Threat{Id: CAPECID(p.ID), Name: p.Name, Description: string(p.Description), Custom: custom})
+		imported++
+	}
+
+	return imported, nil
+}
+
+// AttackPatterns returns the ids of the attack patterns of the library exploiting a weakness: the ones listed by the
+// weakness, and the ones listing the weakness, sorted.
+func (l *Library) AttackPatterns(weakness Threat) []string {
+	found := map[string]bool{}
+	for _, id := range CustomStrings(weakness.Custom, "attack_patterns") {
+		if _, ok := l.Threats[id]; ok {
+			found[id] = true
+		}
+	}
+	for id, t := range l.Threats {
+		for _, w := range CustomStrings(t.Custom, "weaknesses") {
+			if w == weakness.Id {
+				found[id] = true
+			}
+		}
+	}
+
+	return sortedKeys(found)
+}
+
+// CustomStrings returns the strings of a custom value, a string or a list of strings, as read from JSON or imported.
+func CustomStrings(custom Custom, key string) []string {
+	switch value := custom[key].(type) {
+	case string:
+		return []string{value}
+	case []string:
+		return value
+	case []interface{}:
+		var values []string
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+
+		return values
+	}
+
+	return nil
+}
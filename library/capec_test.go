@@ -0,0 +1,91 @@
+package library
+
+import (
+	"reflect"
+	"testing"
+)
+
+const capecCatalogue = `<?xml version="1.0" encoding="UTF-8"?>
+<Attack_Pattern_Catalog xmlns="http://capec.mitre.org/capec-3" xmlns:xhtml="http://www.w3.org/1999/xhtml" Name="CAPEC" Version="3.9">
+ <Attack_Patterns>
+  <Attack_Pattern ID="63" Name="Cross-Site Scripting (XSS)" Abstraction="Standard" Status="Draft">
+   <Description>An adversary embeds malicious scripts
+     in content.</Description>
+   <Likelihood_Of_Attack>High</Likelihood_Of_Attack>
+   <Typical_Severity>Very High</Typical_Severity>
+   <Mitigations>
+    <Mitigation>Design: Use browser technologies that do not allow client side scripting.</Mitigation>
+    <Mitigation><xhtml:p>Implementation: Perform input validation</xhtml:p><xhtml:p>for all remote content.</xhtml:p></Mitigation>
+   </Mitigations>
+   <Related_Weaknesses><Related_Weakness CWE_ID="79"/><Related_Weakness CWE_ID="20"/></Related_Weaknesses>
+  </Attack_Pattern>
+  <Attack_Pattern ID="66" Name="SQL Injection" Abstraction="Standard" Status="Draft">
+   <Description>SQL.</Description>
+   <Related_Weaknesses><Related_Weakness CWE_ID="89"/></Related_Weaknesses>
+  </Attack_Pattern>
+  <Attack_Pattern ID="1" Name="Old" Status="Deprecated"><Description>Old.</Description></Attack_Pattern>
+ </Attack_Patterns>
+</Attack_Pattern_Catalog>`
+
+func TestImportCAPEC(t *testing.T) {
+	tests := []struct {
+		name   string
+		linked bool
+		want   []string
+	}{
+		{name: "all", want: []string{"#capec-63", "#capec-66"}},
+		{name: "linked", linked: true, want: []string{"#capec-63"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := writeFile(t, t.TempDir(), "capec.xml", capecCatalogue)
+
+			l := newLibrary()
+			l.Threats["#cwe-79"] = Threat{Id: "#cwe-79", Name: "Cross-site Scripting", Custom: Custom{"kind": KindWeakness}}
+			n, err := l.ImportCAPEC(filename, tt.linked)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(tt.want) {
+				t.Errorf("ImportCAPEC() = %d, want %d", n, len(tt.want))
+			}
+			if got := l.AttackPatterns(l.Threats["#cwe-79"]); !reflect.DeepEqual(got, []string{"#capec-63"}) {
+				t.Errorf("AttackPatterns() = %v, want [#capec-63]", got)
+			}
+			for _, id := range tt.want {
+				if _, ok := l.Threats[id]; !ok {
+					t.Errorf("ImportCAPEC() did not import %s", id)
+				}
+			}
+		})
+	}
+}
+
+func TestImportCAPECThreat(t *testing.T) {
+	filename := writeFile(t, t.TempDir(), "capec.xml", capecCatalogue)
+
+	l := newLibrary()
+	if _, err := l.ImportCAPEC(filename, false); err != nil {
+		t.Fatal(err)
+	}
+
+	pattern := l.Threats["#capec-63"]
+	if pattern.Name != "Cross-Site Scripting (XSS)" || pattern.Description != "An adversary embeds malicious scripts in content." || !pattern.Seeded {
+		t.Errorf("ImportCAPEC() imported %+v", pattern)
+	}
+	for key, want := range map[string][]string{
+		"kind":       {KindAttackPattern},
+		"likelihood": {"High"},
+		"severity":   {"Very High"},
+		"weaknesses": {"#cwe-79", "#cwe-20"},
+		"candidate_controls": {
+			"Design: Use browser technologies that do not allow client side scripting.",
+			"Implementation: Perform input validation for all remote content.",
+		},
+	} {
+		if got := CustomStrings(pattern.Custom, key); !reflect.DeepEqual(got, want) {
+			t.Errorf("ImportCAPEC() custom %s = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package library
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// cweURL is the page of a weakness on the CWE website.
+const cweURL = "https://cwe.mitre.org/data/definitions/%s.html"
+
+// Subset of the MITRE CWE XML catalogue, cwec_vX.xml.
+type (
+	cweCatalog struct {
+		Weaknesses []cweWeakness `xml:"Weaknesses>Weakness"`
+		Categories []cweCategory `xml:"Categories>Category"`
+		Views      []cweView     `xml:"Views>View"`
+	}
+	cweWeakness struct {
+		ID                string             `xml:"ID,attr"`
+		Name              string             `xml:"Name,attr"`
+		Abstraction       string             `xml:"Abstraction,attr"`
+		Status            string             `xml:"Status,attr"`
+		Description       string             `xml:"Description"`
+		Likelihood        string             `xml:"Likelihood_Of_Exploit"`
+		RelatedWeaknesses []cweRelation      `xml:"Related_Weaknesses>Related_Weakness"`
+		AttackPatterns    []cweAttackPattern `xml:"Related_Attack_Patterns>Related_Attack_Pattern"`
+	}
+	cweRelation struct {
+		Nature string `xml:"Nature,attr"`
+		CWEID  string `xml:"CWE_ID,attr"`
+		ViewID string `xml:"View_ID,attr"`
+	}
+	cweAttackPattern struct {
+		CAPECID string `xml:"CAPEC_ID,attr"`
+	}
+	cweCategory struct {
+		ID      string      `xml:"ID,attr"`
+		Members []cweMember `xml:"Relationships>Has_Member"`
+	}
+	cweView struct {
+		ID      string      `xml:"ID,attr"`
+		Name    string      `xml:"Name,attr"`
+		Type    string      `xml:"Type,attr"`
+		Members []cweMember `xml:"Members>Has_Member"`
+	}
+	cweMember struct {
+		CWEID string `xml:"CWE_ID,attr"`
+	}
+)
+
+// Kinds of the catalogue entries imported as threats, kept as the kind custom data. They are references to assess
+// the threat model against rather than threats of the project, unless an annotation references them.
+const (
+	KindWeakness      = "weakness"
+	KindAttackPattern = "attack_pattern"
+)
+
+// CWEID returns the id of the threat of a CWE weakness, like #cwe-79.
+func CWEID(id string) string {
+	return "#cwe-" + id
+}
+
+// CAPECID returns the id of a CAPEC attack pattern, like #capec-63.
+func CAPECID(id string) string {
+	return "#capec-" + id
+}
+
+// ImportCWE adds the weaknesses of a MITRE CWE XML catalogue to the library as threats with #cwe-79 like ids, and
+// returns how many were imported. Their likelihood of exploit, related weaknesses and attack patterns and their page
+// on the CWE website are kept as custom data. When view is set, like 1425 or CWE-1425 for a CWE Top 25, only the
+// weaknesses the view holds are imported. Deprecated weaknesses are skipped.
+func (l *Library) ImportCWE(filename, view string) (int, error) {
+	file, err := os.ReadFile(filename)
+	if err != nil {
+		return 0, eris.Wrapf(err, "failed to read %s", filename)
+	}
+
+	var catalog cweCatalog
+	if err := xml.Unmarshal(file, &catalog); err != nil {
+		return 0, eris.Wrapf(err, "failed to understand %s", filename)
+	}
+
+	var members map[string]bool
+	if view != "" {
+		if members, err = catalog.members(strings.TrimPrefix(strings.ToUpper(view), "CWE-")); err != nil {
+			return 0, err
+		}
+	}
+
+	imported := 0
+	for _, w := range catalog.Weaknesses {
+		if w.Status == "Deprecated" || (members != nil && !members[w.ID]) {
+			continue
+		}
+
+		custom := Custom{
+			"kind":        KindWeakness,
+			"abstraction": w.Abstraction,
+			"url":         fmt.Sprintf(cweURL, w.ID),
+		}
+		if w.Likelihood != "" {
+			custom["likelihood"] = w.Likelihood
+		}
+		related := map[string]bool{}
+		var relationships []interface{}
+		for _, r := range w.RelatedWeaknesses {
+			relationship := r.Nature + " " + CWEID(r.CWEID)
+			if !related[relationship] {
+				related[relationship] = true
+				relationships = append(relationships, relationship)
+			}
+		}
+		if len(relationships) > 0 {
+			custom["related_weaknesses"] = relationships
+		}
+		var patterns []interface{}
+		for _, p := range w.AttackPatterns {
+			patterns = append(patterns, CAPECID(p.CAPECID))
+		}
+		if len(patterns) > 0 {
+			custom["attack_patterns"] = patterns
+		}
+
+		l.importThreat(Threat{Id: CWEID(w.ID), Name: w.Name, Description: collapse(w.Description), Custom: custom})
+		imported++
+	}
+
+	return imported, nil
+}
+
+// AssessedThreats returns the threats of the library indexed by id, without the imported weaknesses and attack
+// patterns the threat model does not reference.
+func (l *Library) AssessedThreats() map[string]Threat {
+	referenced := map[string]bool{}
+	for _, m := range l.ThreatModel.Mitigations {
+		referenced[m.Threat] = true
+	}
+	for _, e := range l.ThreatModel.Exposures {
+		referenced[e.Threat] = true
+	}
+	for _, a := range l.ThreatModel.Acceptances {
+		referenced[a.Threat] = true
+	}
+	for _, t := range l.ThreatModel.Transfers {
+		referenced[t.Threat] = true
+	}
+
+	threats := map[string]Threat{}
+	for id, t := range l.Threats {
+		kind := CustomStrings(t.Custom, "kind")
+		if len(kind) == 0 || (kind[0] != KindWeakness && kind[0] != KindAttackPattern) || referenced[t.Name] {
+			threats[id] = t
+		}
+	}
+
+	return threats
+}
+
+// members returns the ids of the weaknesses of a view: its members, the members of the categories it holds, and,
+// for graph views like Research Concepts, the weaknesses which are children of its members in the view.
+func (c *cweCatalog) members(view string) (map[string]bool, error) {
+	var v *cweView
+	for i := range c.Views {
+		if c.Views[i].ID == view {
+			v = &c.Views[i]
+		}
+	}
+	if v == nil {
+		return nil, eris.Errorf("no view CWE-%s in the catalogue", view)
+	}
+	if len(v.Members) == 0 {
+		return nil, eris.Errorf("view CWE-%s (%s) does not list its members", view, v.Name)
+	}
+
+	categories := map[string][]cweMember{}
+	for _, category := range c.Categories {
+		categories[category.ID] = category.Members
+	}
+
+	members := map[string]bool{}
+	for _, m := range v.Members {
+		members[m.CWEID] = true
+		for _, member := range categories[m.CWEID] {
+			members[member.CWEID] = true
+		}
+	}
+
+	for added := true; added; {
+		added = false
+		for _, w := range c.Weaknesses {
+			for _, r := range w.RelatedWeaknesses {
+				if r.Nature == "ChildOf" && r.ViewID == view && members[r.CWEID] && !members[w.ID] {
+					members[w.ID] = true
+					added = true
+				}
+			}
+		}
+	}
+
+	return members, nil
+}
+
+// importThreat adds an imported threat to the library, seeded. An existing threat keeps its name, unless it is only its id
+// after being referenced by an annotation, its description unless it has none, and its custom data.
+func (l *Library) importThreat(threat Threat) {
+	threat.Seeded = true
+	existing, ok := l.Threats[threat.Id]
+	if !ok {
+		l.Threats[threat.Id] = threat
+
+		return
+	}
+
+	existing.Seeded = true
+	if existing.Name == existing.Id {
+		existing.Name = threat.Name
+	}
+	if existing.Description == "" {
+		existing.Description = threat.Description
+	}
+	if existing.Custom == nil {
+		existing.Custom = Custom{}
+	}
+	for key, value := range threat.Custom {
+		if _, ok := existing.Custom[key]; !ok {
+			existing.Custom[key] = value
+		}
+	}
+	l.Threats[threat.Id] = existing
+}
+
+// collapse replaces the runs of white space of a text with single spaces.
+func collapse(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,108 @@
+package library
+
+import (
+	"reflect"
+	"testing"
+)
+
+const cweCatalogue = `<?xml version="1.0" encoding="UTF-8"?>
+<Weakness_Catalog Name="CWE" Version="4.14" xmlns="http://cwe.mitre.org/cwe-7">
+ <Weaknesses>
+  <Weakness ID="79" Name="Cross-site Scripting" Abstraction="Base" Status="Stable">
+   <Description>The product does not neutralize
+     user-controllable input.</Description>
+   <Related_Weaknesses>
+    <Related_Weakness Nature="ChildOf" CWE_ID="74" View_ID="1000"/>
+    <Related_Weakness Nature="ChildOf" CWE_ID="74" View_ID="1003"/>
+   </Related_Weaknesses>
+   <Likelihood_Of_Exploit>High</Likelihood_Of_Exploit>
+   <Related_Attack_Patterns><Related_Attack_Pattern CAPEC_ID="63"/></Related_Attack_Patterns>
+  </Weakness>
+  <Weakness ID="74" Name="Injection" Abstraction="Class" Status="Incomplete"><Description>Injection.</Description></Weakness>
+  <Weakness ID="89" Name="SQL Injection" Abstraction="Base" Status="Stable">
+   <Description>SQL.</Description>
+   <Related_Weaknesses><Related_Weakness Nature="ChildOf" CWE_ID="74" View_ID="1000"/></Related_Weaknesses>
+  </Weakness>
+  <Weakness ID="1" Name="Old" Status="Deprecated"><Description>Old.</Description></Weakness>
+ </Weaknesses>
+ <Categories><Category ID="990" Name="Category"><Relationships><Has_Member CWE_ID="89" View_ID="1425"/></Relationships></Category></Categories>
+ <Views>
+  <View ID="1425" Name="Top 25" Type="Graph"><Members><Has_Member CWE_ID="79" View_ID="1425"/><Has_Member CWE_ID="990" View_ID="1425"/></Members></View>
+  <View ID="1000" Name="Research Concepts" Type="Graph"><Members><Has_Member CWE_ID="74" View_ID="1000"/></Members></View>
+  <View ID="1400" Name="Implicit" Type="Implicit"><Filter>/x</Filter></View>
+ </Views>
+</Weakness_Catalog>`
+
+func TestImportCWE(t *testing.T) {
+	tests := []struct {
+		name    string
+		view    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "all", want: []string{"#cwe-74", "#cwe-79", "#cwe-89"}},
+		{name: "members and categories", view: "CWE-1425", want: []string{"#cwe-79", "#cwe-89"}},
+		{name: "children of the members", view: "1000", want: []string{"#cwe-74", "#cwe-89", "#cwe-79"}},
+		{name: "unknown view", view: "7", wantErr: true},
+		{name: "view without members", view: "1400", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := writeFile(t, t.TempDir(), "cwec.xml", cweCatalogue)
+
+			l := newLibrary()
+			n, err := l.ImportCWE(filename, tt.view)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ImportCWE() error = %v, want error %v", err, tt.wantErr)
+			}
+			if n != len(tt.want) || len(l.Threats) != len(tt.want) {
+				t.Errorf("ImportCWE() imported %d threats %v, want %v", n, sortedKeys(l.Threats), tt.want)
+			}
+			for _, id := range tt.want {
+				if _, ok := l.Threats[id]; !ok {
+					t.Errorf("ImportCWE() did not import %s", id)
+				}
+			}
+		})
+	}
+}
+
+func TestImportCWEThreat(t *testing.T) {
+	filename := writeFile(t, t.TempDir(), "cwec.xml", cweCatalogue)
+
+	l := newLibrary()
+	l.Threats["#cwe-79"] = Threat{Id: "#cwe-79", Name: "#cwe-79", Custom: Custom{"owner": "web team"}}
+	if _, err := l.ImportCWE(filename, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	threat := l.Threats["#cwe-79"]
+	if threat.Name != "Cross-site Scripting" || threat.Description != "The product does not neutralize user-controllable input." || !threat.Seeded {
+		t.Errorf("ImportCWE() imported %+v", threat)
+	}
+	for key, want := range map[string][]string{
+		"kind":               {KindWeakness},
+		"likelihood":         {"High"},
+		"related_weaknesses": {"ChildOf #cwe-74"},
+		"attack_patterns":    {"#capec-63"},
+		"owner":              {"web team"},
+	} {
+		if got := CustomStrings(threat.Custom, key); !reflect.DeepEqual(got, want) {
+			t.Errorf("ImportCWE() custom %s = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestAssessedThreats(t *testing.T) {
+	l := newLibrary()
+	l.Threats["#cwe-79"] = Threat{Id: "#cwe-79", Name: "Cross-site Scripting", Custom: Custom{"kind": KindWeakness}}
+	l.Threats["#cwe-89"] = Threat{Id: "#cwe-89", Name: "SQL Injection", Custom: Custom{"kind": KindWeakness}}
+	l.Threats["#capec-63"] = Threat{Id: "#capec-63", Name: "Cross-Site Scripting (XSS)", Custom: Custom{"kind": KindAttackPattern}}
+	l.Threats["Spoofing"] = Threat{Id: "Spoofing", Name: "Spoofing"}
+	l.ThreatModel.Exposures = []Exposure{{Threat: "Cross-site Scripting", Component: "WebApp:Web"}}
+
+	if got, want := sortedKeys(l.AssessedThreats()), []string{"#cwe-79", "Spoofing"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AssessedThreats() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package library
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// The number of lines and of characters of code kept as the source of an annotation.
+const (
+	snippetLines  = 10
+	snippetLength = 1000
+)
+
+// ParseGo adds to the library the annotations found in the comments of a Go source file.
+// Each annotation is linked to the declaration it documents or belongs to.
+func (l *Library) ParseGo(filename string, data []byte) error {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, filename, data, parser.ParseComments)
+	if err != nil {
+		return eris.Wrapf(err, "failed to parse %s", filename)
+	}
+
+	for _, group := range f.Comments {
+		code := declaration(fset, f, group, data)
+
+		for _, c := range group.List {
+			l.Parse(commentText(c.Text), Source{
+				Code:     code,
+				Filename: filename,
+				Line:     fset.Position(c.Pos()).Line,
+			})
+		}
+	}
+
+	return nil
+}
+
+// declaration returns the snippet of the top level declaration enclosing the comment group,
+// or of the one following it when the comment sits between declarations.
+func declaration(fset *token.FileSet, f *ast.File, group *ast.CommentGroup, data []byte) string {
+	for _, decl := range f.Decls {
+		if decl.End() < group.Pos() {
+			continue
+		}
+
+		return snippet(string(data[fset.Position(decl.Pos()).Offset:fset.Position(decl.End()).Offset]))
+	}
+
+	return ""
+}
+
+// snippet returns the beginning of the code of a declaration, so that the threat model does not hold a copy of a
+// large declaration for each of its annotations.
+func snippet(code string) string {
+	truncated := false
+	if lines := strings.SplitN(code, "\n", snippetLines+1); len(lines) > snippetLines {
+		code, truncated = strings.Join(lines[:snippetLines], "\n"), true
+	}
+	if runes := []rune(code); len(runes) > snippetLength {
+		code, truncated = string(runes[:snippetLength]), true
+	}
+	if truncated {
+		code += "\n…"
+	}
+
+	return code
+}
+
+// commentText strips the markers of a line or block comment.
+func commentText(text string) string {
+	if strings.HasPrefix(text, "//") {
+		return text[2:]
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+}
@@ -0,0 +1,262 @@
+package library
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+const (
+	// historyDir is the directory of the threat model directory the snapshots are archived in.
+	historyDir = "history"
+	// snapshotLayout names the snapshots after the time they are taken at, so that they sort chronologically.
+	snapshotLayout = "20060102T150405Z"
+	snapshotExt    = ".json.gz"
+)
+
+// Snapshot is an archived state of the threat model, with the entries of the library it references.
+type Snapshot struct {
+	Taken       time.Time            `json:"taken"`
+	Components  map[string]Component `json:"components"`
+	Controls    map[string]Control   `json:"controls"`
+	Threats     map[string]Threat    `json:"threats"`
+	ThreatModel Threatmodel          `json:"threat_model"`
+}
+
+// SaveSnapshot archives a gzip compressed snapshot of the threat model in the history directory of the threat model
+// directory, unless its threat model holds the same annotations as the one of the latest snapshot. It then removes the oldest
+// snapshots to keep at most retention of them, or all of them when retention is 0. It reports whether a snapshot was saved.
+func (l *Library) SaveSnapshot(dir string, taken time.Time, retention int) (bool, error) {
+	history := filepath.Join(dir, historyDir)
+	if err := os.MkdirAll(history, 0o700); err != nil {
+		return false, eris.Wrapf(err, "failed to create %s", history)
+	}
+
+	snapshots, err := ReadSnapshots(dir)
+	if err != nil {
+		return false, err
+	}
+	if len(snapshots) > 0 && snapshots[len(snapshots)-1].ThreatModel.Same(l.ThreatModel) {
+		return false, nil
+	}
+
+	components, controls, threats := l.ThreatModel.references()
+	data, err := json.Marshal(Snapshot{
+		Taken:       taken.UTC(),
+		Components:  referenced(l.Components, components, func(c Component) string { return c.Name }),
+		Controls:    referenced(l.Controls, controls, func(c Control) string { return c.Name }),
+		Threats:     referenced(l.Threats, threats, func(t Threat) string { return t.Name }),
+		ThreatModel: l.ThreatModel,
+	})
+	if err != nil {
+		return false, eris.Wrap(err, "failed to marshal snapshot")
+	}
+
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write(data); err != nil {
+		return false, eris.Wrap(err, "failed to compress snapshot")
+	}
+	if err := w.Close(); err != nil {
+		return false, eris.Wrap(err, "failed to compress snapshot")
+	}
+
+	// Snapshots taken in the same second, like with a pinned timestamp, are numbered after the latest one so that none
+	// is overwritten and they keep sorting chronologically.
+	filenames, err := snapshotFiles(history)
+	if err != nil {
+		return false, err
+	}
+	latest := ""
+	if len(filenames) > 0 {
+		latest = filepath.Base(filenames[len(filenames)-1])
+	}
+	name := taken.UTC().Format(snapshotLayout)
+	filename := name + snapshotExt
+	for n := 2; exists(filepath.Join(history, filename)) || (strings.HasPrefix(latest, name) && filename <= latest); n++ {
+		filename = fmt.Sprintf("%s_%03d%s", name, n, snapshotExt)
+	}
+	filename = filepath.Join(history, filename)
+	if err := os.WriteFile(filename, b.Bytes(), 0o600); err != nil {
+		return false, eris.Wrapf(err, "failed to write %s", filename)
+	}
+
+	return true, prune(history, retention)
+}
+
+// references returns the components, controls and threats the annotations of the threat model reference.
+func (tm Threatmodel) references() (components, controls, threats map[string]bool) {
+	components, controls, threats = map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, m := range tm.Mitigations {
+		components[m.Component], controls[m.Control], threats[m.Threat] = true, true, true
+	}
+	for _, e := range tm.Exposures {
+		components[e.Component], threats[e.Threat] = true, true
+	}
+	for _, a := range tm.Acceptances {
+		components[a.Component], threats[a.Threat] = true, true
+	}
+	for _, t := range tm.Transfers {
+		components[t.SourceComponent], components[t.DestinationComponent], threats[t.Threat] = true, true, true
+	}
+	for _, c := range tm.Connections {
+		components[c.SourceComponent], components[c.DestinationComponent] = true, true
+	}
+	for _, r := range tm.Reviews {
+		components[r.Component] = true
+	}
+	for _, t := range tm.Tests {
+		components[t.Component], controls[t.Control] = true, true
+	}
+
+	return components, controls, threats
+}
+
+// referenced returns the entries of the index referenced by id or by name, leaving out the catalogues imported into
+// the library.
+func referenced[T any](index map[string]T, references map[string]bool, nameOf func(T) string) map[string]T {
+	entries := map[string]T{}
+	for id, entry := range index {
+		if references[id] || references[nameOf(entry)] {
+			entries[id] = entry
+		}
+	}
+
+	return entries
+}
+
+// exists reports whether a file exists.
+func exists(filename string) bool {
+	_, err := os.Stat(filename)
+
+	return err == nil
+}
+
+// prune removes the oldest snapshots of the history directory to keep at most retention of them.
+func prune(history string, retention int) error {
+	if retention <= 0 {
+		return nil
+	}
+
+	filenames, err := snapshotFiles(history)
+	if err != nil {
+		return err
+	}
+
+	for len(filenames) > retention {
+		if err := os.Remove(filenames[0]); err != nil {
+			return eris.Wrapf(err, "failed to remove %s", filenames[0])
+		}
+		filenames = filenames[1:]
+	}
+
+	return nil
+}
+
+// snapshotFiles returns the snapshot files of the history directory, the oldest first.
+func snapshotFiles(history string) ([]string, error) {
+	entries, err := os.ReadDir(history)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to read %s", history)
+	}
+
+	var filenames []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), snapshotExt) {
+			filenames = append(filenames, filepath.Join(history, entry.Name()))
+		}
+	}
+	sort.Strings(filenames)
+
+	return filenames, nil
+}
+
+// ReadSnapshots reads the snapshots archived in the history directory of the threat model directory, the oldest first.
+func ReadSnapshots(dir string) ([]Snapshot, error) {
+	filenames, err := snapshotFiles(filepath.Join(dir, historyDir))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]Snapshot, 0, len(filenames))
+	for _, filename := range filenames {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to open %s", filename)
+		}
+
+		snapshot, err := readSnapshot(f)
+		f.Close()
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to understand %s", filename)
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// readSnapshot decompresses and decodes a snapshot.
+func readSnapshot(r io.Reader) (Snapshot, error) {
+	var snapshot Snapshot
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return snapshot, eris.Wrap(err, "failed to decompress snapshot")
+	}
+	defer gz.Close()
+
+	if err := json.NewDecoder(gz).Decode(&snapshot); err != nil {
+		return snapshot, eris.Wrap(err, "failed to decode snapshot")
+	}
+
+	return snapshot, nil
+}
+
+// Same reports whether two threat models hold the same annotations, regardless of their source locations.
+func (tm Threatmodel) Same(other Threatmodel) bool {
+	return strings.Join(tm.Keys(), "\n") == strings.Join(other.Keys(), "\n")
+}
+
+// Keys returns a key identifying each annotation of the threat model regardless of its source location,
+// so that threat models can be compared as code moves.
+func (tm Threatmodel) Keys() []string {
+	var keys []string
+	for _, m := range tm.Mitigations {
+		keys = append(keys, "mitigates "+m.Component+" against "+m.Threat+" with "+m.Control)
+	}
+	for _, e := range tm.Exposures {
+		keys = append(keys, "exposes "+e.Component+" to "+e.Threat+" with "+e.Details)
+	}
+	for _, a := range tm.Acceptances {
+		keys = append(keys, "accepts "+a.Threat+" to "+a.Component+" with "+a.Details)
+	}
+	for _, t := range tm.Transfers {
+		keys = append(keys, "transfers "+t.Threat+" from "+t.SourceComponent+" to "+t.DestinationComponent+" with "+t.Details)
+	}
+	for _, c := range tm.Connections {
+		keys = append(keys, "connects "+c.SourceComponent+" "+c.Direction+" "+c.DestinationComponent+" with "+c.Details)
+	}
+	for _, r := range tm.Reviews {
+		keys = append(keys, "reviews "+r.Component+" "+r.Details)
+	}
+	for _, t := range tm.Tests {
+		keys = append(keys, "tests "+t.Control+" for "+t.Component)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,113 @@
+package library
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveSnapshot(t *testing.T) {
+	l := newLibrary()
+	l.Parse("@mitigates WebApp:Web against XSS with escaping", Source{Filename: "web.go", Line: 1})
+	l.Threats["#cwe-89"] = Threat{Id: "#cwe-89", Name: "SQL Injection"}
+	l.Controls["#unused"] = Control{Id: "#unused", Name: "unused"}
+	l.Components["#api"] = Component{Id: "#api", Name: "WebApp:API"}
+
+	dir := t.TempDir()
+	taken := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	saved, err := l.SaveSnapshot(dir, taken, 0)
+	if err != nil || !saved {
+		t.Fatalf("SaveSnapshot() = %v, %v, want a snapshot", saved, err)
+	}
+
+	snapshots, err := ReadSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 {
+		t.Fatalf("ReadSnapshots() = %d snapshots, want 1", len(snapshots))
+	}
+	s := snapshots[0]
+	if !s.Taken.Equal(taken) || len(s.ThreatModel.Mitigations) != 1 {
+		t.Errorf("ReadSnapshots()[0] = %+v, want the threat model taken at %v", s, taken)
+	}
+	if _, ok := s.Threats["XSS"]; !ok || len(s.Threats) != 1 {
+		t.Errorf("ReadSnapshots()[0].Threats = %v, want only XSS", s.Threats)
+	}
+	if _, ok := s.Controls["escaping"]; !ok || len(s.Controls) != 1 {
+		t.Errorf("ReadSnapshots()[0].Controls = %v, want only escaping", s.Controls)
+	}
+	if _, ok := s.Components["WebApp:Web"]; !ok || len(s.Components) != 1 {
+		t.Errorf("ReadSnapshots()[0].Components = %v, want only WebApp:Web", s.Components)
+	}
+}
+
+func TestSaveSnapshotHistory(t *testing.T) {
+	annotations := []string{
+		"@mitigates WebApp:Web against XSS with escaping",
+		"@exposes WebApp:DB to SQL injection with raw queries",
+		"@accepts DoS to WebApp:Web with rate limited upstream",
+	}
+
+	tests := []struct {
+		name      string
+		retention int
+		changed   []bool
+		want      []string
+	}{
+		{
+			name:    "numbered in the same second",
+			changed: []bool{true, true, true},
+			want:    []string{"20240102T030405Z.json.gz", "20240102T030405Z_002.json.gz", "20240102T030405Z_003.json.gz"},
+		},
+		{
+			name:    "unchanged threat model",
+			changed: []bool{true, false, false},
+			want:    []string{"20240102T030405Z.json.gz"},
+		},
+		{
+			name:      "retention",
+			retention: 2,
+			changed:   []bool{true, true, true},
+			want:      []string{"20240102T030405Z_002.json.gz", "20240102T030405Z_003.json.gz"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			l := newLibrary()
+			for i, changed := range tt.changed {
+				if changed {
+					l.Parse(annotations[i], Source{Filename: "main.go", Line: i + 1})
+				}
+
+				saved, err := l.SaveSnapshot(dir, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), tt.retention)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if saved != changed {
+					t.Errorf("SaveSnapshot() #%d = %v, want %v", i, saved, changed)
+				}
+			}
+
+			files, err := snapshotFiles(filepath.Join(dir, historyDir))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, file := range files {
+				got = append(got, filepath.Base(file))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("snapshots = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("snapshots = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
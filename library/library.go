@@ -3,125 +3,111 @@ package library
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/rotisserie/eris"
 )
 
 type (
+	// Custom holds the free-form data of a library entry or of an annotation, like the custom keys of threatspec.
+	Custom map[string]interface{}
+	// Source locates the annotation a threat model entry was parsed from.
+	Source struct {
+		Annotation string `json:"annotation"`
+		Code       string `json:"code"`
+		Filename   string `json:"filename"`
+		Line       int    `json:"line"`
+	}
 	Component struct {
 		Id          string     `json:"id"`
 		RunId       string     `json:"run_id"`
 		Name        string     `json:"name"`
 		Description string     `json:"description"`
 		Paths       [][]string `json:"paths"`
-		Custom      struct{}   `json:"custom"`
+		Custom      Custom     `json:"custom"`
+		Seeded      bool       `json:"seeded,omitempty"`
 	}
 	Control struct {
-		Id          string   `json:"id"`
-		RunId       string   `json:"run_id"`
-		Name        string   `json:"name"`
-		Description string   `json:"description"`
-		Custom      struct{} `json:"custom"`
+		Id          string `json:"id"`
+		RunId       string `json:"run_id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Custom      Custom `json:"custom"`
+		Seeded      bool   `json:"seeded,omitempty"`
 	}
 	Threat struct {
-		Id          string   `json:"id"`
-		RunId       string   `json:"run_id"`
-		Name        string   `json:"name"`
-		Description string   `json:"description"`
-		Custom      struct{} `json:"custom"`
+		Id          string `json:"id"`
+		RunId       string `json:"run_id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Custom      Custom `json:"custom"`
+		Seeded      bool   `json:"seeded,omitempty"`
 	}
 	Mitigate struct {
-		Control     string   `json:"control"`
-		Threat      string   `json:"threat"`
-		Component   string   `json:"component"`
-		Description string   `json:"description"`
-		Custom      struct{} `json:"custom"`
-		Source      struct {
-			Annotation string `json:"annotation"`
-			Code       string `json:"code"`
-			Filename   string `json:"filename"`
-			Line       int    `json:"line"`
-		} `json:"source"`
+		Control     string `json:"control"`
+		Threat      string `json:"threat"`
+		Component   string `json:"component"`
+		Description string `json:"description"`
+		Custom      Custom `json:"custom"`
+		Source      Source `json:"source"`
 	}
 	Acceptance struct {
-		Threat      string   `json:"threat"`
-		Component   string   `json:"component"`
-		Details     string   `json:"details"`
-		Description string   `json:"description"`
-		Custom      struct{} `json:"custom"`
-		Source      struct {
-			Annotation string `json:"annotation"`
-			Code       string `json:"code"`
-			Filename   string `json:"filename"`
-			Line       int    `json:"line"`
-		} `json:"source"`
+		Threat      string `json:"threat"`
+		Component   string `json:"component"`
+		Details     string `json:"details"`
+		Description string `json:"description"`
+		Custom      Custom `json:"custom"`
+		Source      Source `json:"source"`
 	}
 	Exposure struct {
-		Threat      string   `json:"threat"`
-		Component   string   `json:"component"`
-		Details     string   `json:"details"`
-		Description string   `json:"description"`
-		Custom      struct{} `json:"custom"`
-		Source      struct {
-			Annotation string `json:"annotation"`
-			Code       string `json:"code"`
-			Filename   string `json:"filename"`
-			Line       int    `json:"line"`
-		} `json:"source"`
+		Threat      string `json:"threat"`
+		Component   string `json:"component"`
+		Details     string `json:"details"`
+		Description string `json:"description"`
+		Custom      Custom `json:"custom"`
+		Source      Source `json:"source"`
 	}
 	Transfer struct {
-		Threat               string   `json:"threat"`
-		SourceComponent      string   `json:"source_component"`
-		DestinationComponent string   `json:"destination_component"`
-		Details              string   `json:"details"`
-		Description          string   `json:"description"`
-		Custom               struct{} `json:"custom"`
-		Source               struct {
-			Annotation string `json:"annotation"`
-			Code       string `json:"code"`
-			Filename   string `json:"filename"`
-			Line       int    `json:"line"`
-		} `json:"source"`
+		Threat               string `json:"threat"`
+		SourceComponent      string `json:"source_component"`
+		DestinationComponent string `json:"destination_component"`
+		Details              string `json:"details"`
+		Description          string `json:"description"`
+		Custom               Custom `json:"custom"`
+		Source               Source `json:"source"`
 	}
 	Connection struct {
-		SourceComponent      string   `json:"source_component"`
-		DestinationComponent string   `json:"destination_component"`
-		Direction            string   `json:"direction"`
-		Details              string   `json:"details"`
-		Description          string   `json:"description"`
-		Custom               struct{} `json:"custom"`
-		Source               struct {
-			Annotation string `json:"annotation"`
-			Code       string `json:"code"`
-			Filename   string `json:"filename"`
-			Line       int    `json:"line"`
-		} `json:"source"`
+		SourceComponent      string `json:"source_component"`
+		DestinationComponent string `json:"destination_component"`
+		Direction            string `json:"direction"`
+		Details              string `json:"details"`
+		Description          string `json:"description"`
+		Custom               Custom `json:"custom"`
+		Source               Source `json:"source"`
 	}
 	Review struct {
-		Component   string   `json:"component"`
-		Details     string   `json:"details"`
-		Description string   `json:"description"`
-		Custom      struct{} `json:"custom"`
-		Source      struct {
-			Annotation string `json:"annotation"`
-			Code       string `json:"code"`
-			Filename   string `json:"filename"`
-			Line       int    `json:"line"`
-		} `json:"source"`
+		Component   string `json:"component"`
+		Details     string `json:"details"`
+		Description string `json:"description"`
+		Custom      Custom `json:"custom"`
+		Source      Source `json:"source"`
 	}
 	Test struct {
-		Component   string   `json:"component"`
-		Control     string   `json:"control"`
-		Description string   `json:"description"`
-		Custom      struct{} `json:"custom"`
-		Source      struct {
-			Annotation string `json:"annotation"`
-			Code       string `json:"code"`
-			Filename   string `json:"filename"`
-			Line       int    `json:"line"`
-		} `json:"source"`
+		Component   string `json:"component"`
+		Control     string `json:"control"`
+		Description string `json:"description"`
+		Custom      Custom `json:"custom"`
+		Source      Source `json:"source"`
+	}
+	// Scope describes the source code the threat model was parsed from.
+	Scope struct {
+		Paths []string `json:"paths"`
+		Files int      `json:"files"`
+		// Languages counts the files parsed per language.
+		Languages map[string]int `json:"languages"`
 	}
 	Threatmodel struct {
 		Mitigations []Mitigate   `json:"mitigations"`
@@ -131,6 +117,7 @@ type (
 		Connections []Connection `json:"connections"`
 		Reviews     []Review     `json:"reviews"`
 		Tests       []Test       `json:"tests"`
+		Scope       Scope        `json:"scope"`
 		RunId       string       `json:"run_id"`
 	}
 	Library struct {
@@ -156,265 +143,524 @@ const (
 )
 
 // Parse deduces from comment and adds to the library everything that is compatible with the specification.
-func (l *Library) Parse(comment string) {
+// The source locates the first line of the comment, every entry is given the line of its own annotation.
+func (l *Library) Parse(comment string, source Source) {
 	// component
 	re := regexp.MustCompile(componentRe)
-	re.FindStringSubmatch(comment)
-	for _, match := range re.FindAllString(comment, -1) {
-		comp := Component{Name: re.FindStringSubmatch(match)[1]}
+	for _, match := range re.FindAllStringSubmatchIndex(comment, -1) {
+		comp := Component{Name: group(comment, match, 1)}
 		l.addComponent(&comp)
 	}
 
 	// controlRe
 	re = regexp.MustCompile(controlRe)
-	re.FindStringSubmatch(comment)
-	for _, match := range re.FindAllString(comment, -1) {
-		cont := Control{Name: re.FindStringSubmatch(match)[1]}
+	for _, match := range re.FindAllStringSubmatchIndex(comment, -1) {
+		cont := Control{Name: group(comment, match, 1)}
 		l.addControl(&cont)
 	}
 
 	// threatRe
 	re = regexp.MustCompile(threatRe)
-	re.FindStringSubmatch(comment)
-	for _, match := range re.FindAllString(comment, -1) {
-		threat := Threat{Name: re.FindStringSubmatch(match)[1]}
+	for _, match := range re.FindAllStringSubmatchIndex(comment, -1) {
+		threat := Threat{Name: group(comment, match, 1)}
 		l.addThreat(&threat)
 	}
 
 	// mitigateRe
 	re = regexp.MustCompile(mitigateRe)
-	re.FindStringSubmatch(comment)
-	for _, match := range re.FindAllString(comment, -1) {
+	for _, match := range re.FindAllStringSubmatchIndex(comment, -1) {
 		mitigate := Mitigate{
-			Control:   re.FindStringSubmatch(match)[3],
-			Threat:    re.FindStringSubmatch(match)[2],
-			Component: re.FindStringSubmatch(match)[1],
+			Control:   group(comment, match, 3),
+			Threat:    group(comment, match, 2),
+			Component: group(comment, match, 1),
+			Source:    locate(comment, match, source),
 		}
 		l.addMitigate(&mitigate)
 	}
 
 	// acceptRe
 	re = regexp.MustCompile(acceptRe)
-	re.FindStringSubmatch(comment)
-	for _, match := range re.FindAllString(comment, -1) {
+	for _, match := range re.FindAllStringSubmatchIndex(comment, -1) {
 		acceptance := Acceptance{
-			Threat:    re.FindStringSubmatch(match)[2],
-			Component: re.FindStringSubmatch(match)[1],
+			Threat:    group(comment, match, 1),
+			Component: group(comment, match, 2),
+			Details:   group(comment, match, 3),
+			Source:    locate(comment, match, source),
 		}
 		l.addAcceptance(&acceptance)
 	}
 
 	// exposeRe
 	re = regexp.MustCompile(exposeRe)
-	re.FindStringSubmatch(comment)
-	for _, match := range re.FindAllString(comment, -1) {
+	for _, match := range re.FindAllStringSubmatchIndex(comment, -1) {
 		expose := Exposure{
-			Threat:    re.FindStringSubmatch(match)[3],
-			Component: re.FindStringSubmatch(match)[1],
-			Details:   re.FindStringSubmatch(match)[2],
+			Threat:    group(comment, match, 2),
+			Component: group(comment, match, 1),
+			Details:   group(comment, match, 3),
+			Source:    locate(comment, match, source),
 		}
 		l.addExposure(&expose)
 	}
 
 	// transferRe
 	re = regexp.MustCompile(transferRe)
-	re.FindStringSubmatch(comment)
-	for _, match := range re.FindAllString(comment, -1) {
+	for _, match := range re.FindAllStringSubmatchIndex(comment, -1) {
 		transfer := Transfer{
-			Threat:               re.FindStringSubmatch(match)[1],
-			SourceComponent:      re.FindStringSubmatch(match)[2],
-			DestinationComponent: re.FindStringSubmatch(match)[3],
-			Details:              re.FindStringSubmatch(match)[4],
+			Threat:               group(comment, match, 1),
+			SourceComponent:      group(comment, match, 2),
+			DestinationComponent: group(comment, match, 3),
+			Details:              group(comment, match, 4),
+			Source:               locate(comment, match, source),
 		}
 		l.addTransfer(&transfer)
 	}
 
 	// connectRe
 	re = regexp.MustCompile(connectRe)
-	re.FindStringSubmatch(comment)
-	for _, match := range re.FindAllString(comment, -1) {
+	for _, match := range re.FindAllStringSubmatchIndex(comment, -1) {
 		connection := Connection{
-			SourceComponent:      re.FindStringSubmatch(match)[4],
-			DestinationComponent: re.FindStringSubmatch(match)[3],
-			Direction:            re.FindStringSubmatch(match)[2],
-			Details:              re.FindStringSubmatch(match)[1],
+			SourceComponent:      group(comment, match, 1),
+			DestinationComponent: group(comment, match, 3),
+			Direction:            group(comment, match, 2),
+			Details:              group(comment, match, 4),
+			Source:               locate(comment, match, source),
 		}
 		l.addConnection(&connection)
 	}
 
 	// reviewRe
 	re = regexp.MustCompile(reviewRe)
-	re.FindStringSubmatch(comment)
-	for _, match := range re.FindAllString(comment, -1) {
+	for _, match := range re.FindAllStringSubmatchIndex(comment, -1) {
 		review := Review{
-			Component: re.FindStringSubmatch(match)[2],
-			Details:   re.FindStringSubmatch(match)[1],
+			Component: group(comment, match, 1),
+			Details:   group(comment, match, 2),
+			Source:    locate(comment, match, source),
 		}
 		l.addReview(&review)
 	}
 
 	// testRe
 	re = regexp.MustCompile(testRe)
-	re.FindStringSubmatch(comment)
-	for _, match := range re.FindAllString(comment, -1) {
+	for _, match := range re.FindAllStringSubmatchIndex(comment, -1) {
 		test := Test{
-			Component: re.FindStringSubmatch(match)[2],
-			Control:   re.FindStringSubmatch(match)[1],
+			Component: group(comment, match, 2),
+			Control:   group(comment, match, 1),
+			Source:    locate(comment, match, source),
 		}
 		l.addTest(&test)
 	}
 }
 
-func (l *Library) addComponent(component *Component) {
+// group returns the n-th submatch of a match found with FindAllStringSubmatchIndex.
+func group(comment string, match []int, n int) string {
+	return strings.TrimSpace(comment[match[2*n]:match[2*n+1]])
+}
+
+// locate returns the source of a match, offset by the number of lines preceding it in the comment.
+func locate(comment string, match []int, source Source) Source {
+	source.Annotation = strings.TrimSpace(comment[match[0]:match[1]])
+	source.Line += strings.Count(comment[:match[0]], "\n")
+
+	return source
+}
+
+// addComponent adds a component to the library and returns its name. A name which is the id or the name of a
+// component of the library, like #db, refers to it.
+func (l *Library) addComponent(component *Component) string {
 	name, id := parse_name(component.Name)
 
-	if val, ok := l.Components[name]; ok {
-		*component = val
+	if key, ok := lookup(l.Components, component.Name, func(c Component) string { return c.Name }); ok {
+		*component = l.Components[key]
+		name, id = component.Name, key
 	}
 
 	component.Name = name
 	splittedName := strings.Split(component.Name, ":")
-	component.Paths = append(component.Paths, splittedName[:len(splittedName)-1])
-	component.Id = id
+	path := splittedName[:len(splittedName)-1]
+	if !containsPath(component.Paths, path) {
+		component.Paths = append(component.Paths, path)
+	}
+	if component.Id == "" {
+		component.Id = id
+	}
 
-	l.Components[component.Id] = *component
-}
+	l.Components[id] = *component
 
-func (l *Library) addControl(control *Control) {
-	name, id := parse_name(control.Name)
+	return component.Name
+}
 
-	if val, ok := l.Controls[name]; ok {
-		*control = val
+// addControl adds a control to the library and returns its name. A name which is the id or the name of a control of
+// the library refers to it.
+func (l *Library) addControl(control *Control) string {
+	if key, ok := lookup(l.Controls, control.Name, func(c Control) string { return c.Name }); ok {
+		return l.Controls[key].Name
 	}
 
-	control.Name = name
-	control.Id = id
+	control.Name, control.Id = parse_name(control.Name)
 
 	l.Controls[control.Id] = *control
-}
 
-func (l *Library) addThreat(threat *Threat) {
-	name, id := parse_name(threat.Name)
+	return control.Name
+}
 
-	if val, ok := l.Threats[name]; ok {
-		*threat = val
+// addThreat adds a threat to the library and returns its name. A name which is the id or the name of a threat of the
+// library, like #cwe-79 once the CWE catalogue is imported, refers to it.
+func (l *Library) addThreat(threat *Threat) string {
+	if key, ok := lookup(l.Threats, threat.Name, func(t Threat) string { return t.Name }); ok {
+		return l.Threats[key].Name
 	}
 
-	threat.Name = name
-	threat.Id = id
+	threat.Name, threat.Id = parse_name(threat.Name)
 
 	l.Threats[threat.Id] = *threat
+
+	return threat.Name
 }
 
 func (l *Library) addMitigate(mitigate *Mitigate) {
-	l.addControl(&Control{Name: mitigate.Control})
-	l.addThreat(&Threat{Name: mitigate.Threat})
-	l.addComponent(&Component{Name: mitigate.Component})
+	mitigate.Control = l.addControl(&Control{Name: mitigate.Control})
+	mitigate.Threat = l.addThreat(&Threat{Name: mitigate.Threat})
+	mitigate.Component = l.addComponent(&Component{Name: mitigate.Component})
 
 	l.ThreatModel.Mitigations = append(l.ThreatModel.Mitigations, *mitigate)
 }
 
 func (l *Library) addAcceptance(acceptance *Acceptance) {
-	l.addThreat(&Threat{Name: acceptance.Threat})
-	l.addComponent(&Component{Name: acceptance.Component})
+	acceptance.Threat = l.addThreat(&Threat{Name: acceptance.Threat})
+	acceptance.Component = l.addComponent(&Component{Name: acceptance.Component})
 
 	l.ThreatModel.Acceptances = append(l.ThreatModel.Acceptances, *acceptance)
 }
 
 func (l *Library) addExposure(e *Exposure) {
-	l.addThreat(&Threat{Name: e.Threat})
-	l.addComponent(&Component{Name: e.Component})
+	e.Threat = l.addThreat(&Threat{Name: e.Threat})
+	e.Component = l.addComponent(&Component{Name: e.Component})
 
 	l.ThreatModel.Exposures = append(l.ThreatModel.Exposures, *e)
 }
 
 func (l *Library) addTransfer(t *Transfer) {
-	l.addThreat(&Threat{Name: t.Threat})
-	l.addComponent(&Component{Name: t.SourceComponent})
-	l.addComponent(&Component{Name: t.DestinationComponent})
+	t.Threat = l.addThreat(&Threat{Name: t.Threat})
+	t.SourceComponent = l.addComponent(&Component{Name: t.SourceComponent})
+	t.DestinationComponent = l.addComponent(&Component{Name: t.DestinationComponent})
 
 	l.ThreatModel.Transfers = append(l.ThreatModel.Transfers, *t)
 }
 
 func (l *Library) addConnection(c *Connection) {
-	l.addComponent(&Component{Name: c.SourceComponent})
-	l.addComponent(&Component{Name: c.DestinationComponent})
+	c.SourceComponent = l.addComponent(&Component{Name: c.SourceComponent})
+	c.DestinationComponent = l.addComponent(&Component{Name: c.DestinationComponent})
 
 	l.ThreatModel.Connections = append(l.ThreatModel.Connections, *c)
 }
 
 func (l *Library) addReview(r *Review) {
-	l.addComponent(&Component{Name: r.Component})
+	r.Component = l.addComponent(&Component{Name: r.Component})
 
 	l.ThreatModel.Reviews = append(l.ThreatModel.Reviews, *r)
 }
 
 func (l *Library) addTest(t *Test) {
-	l.addComponent(&Component{Name: t.Component})
-	l.addControl(&Control{Name: t.Control})
+	t.Component = l.addComponent(&Component{Name: t.Component})
+	t.Control = l.addControl(&Control{Name: t.Control})
 
 	l.ThreatModel.Tests = append(l.ThreatModel.Tests, *t)
 }
 
+// FindComponent returns the component of the library with the id or the name, as referenced by the annotations.
+func (l *Library) FindComponent(name string) (Component, bool) {
+	return find(l.Components, name, func(c Component) string { return c.Name })
+}
+
+// FindControl returns the control of the library with the id or the name, as referenced by the annotations.
+func (l *Library) FindControl(name string) (Control, bool) {
+	return find(l.Controls, name, func(c Control) string { return c.Name })
+}
+
+// FindThreat returns the threat of the library with the id or the name, as referenced by the annotations.
+func (l *Library) FindThreat(name string) (Threat, bool) {
+	return find(l.Threats, name, func(t Threat) string { return t.Name })
+}
+
+// find returns the entry of the index with the id or the name.
+func find[T any](index map[string]T, name string, nameOf func(T) string) (T, bool) {
+	if key, ok := lookup(index, name, nameOf); ok {
+		return index[key], true
+	}
+
+	var zero T
+
+	return zero, false
+}
+
+// lookup returns the key of the entry of the index with the id or the name, the first key in order when several
+// entries have the name.
+func lookup[T any](index map[string]T, name string, nameOf func(T) string) (string, bool) {
+	_, id := parse_name(name)
+	if _, ok := index[id]; ok {
+		return id, true
+	}
+
+	found := ""
+	for key, entry := range index {
+		if nameOf(entry) == name && (found == "" || key < found) {
+			found = key
+		}
+	}
+
+	return found, found != ""
+}
+
+// MarshalJSON writes an empty object rather than null for missing custom data.
+func (c Custom) MarshalJSON() ([]byte, error) {
+	if c == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(map[string]interface{}(c))
+}
+
+// seedComponent marks a component of the library as seeded, so that run keeps it without annotations, and sets its
+// description if it has none.
+func (l *Library) seedComponent(name, description string) {
+	id, ok := lookup(l.Components, name, func(c Component) string { return c.Name })
+	if !ok {
+		return
+	}
+	c := l.Components[id]
+	c.Seeded = true
+	if c.Description == "" {
+		c.Description = description
+	}
+	l.Components[id] = c
+}
+
+// seedControl marks a control of the library as seeded, and sets its description if it has none.
+func (l *Library) seedControl(name, description string) {
+	id, ok := lookup(l.Controls, name, func(c Control) string { return c.Name })
+	if !ok {
+		return
+	}
+	c := l.Controls[id]
+	c.Seeded = true
+	if c.Description == "" {
+		c.Description = description
+	}
+	l.Controls[id] = c
+}
+
+// seedThreat marks a threat of the library as seeded, and sets its description if it has none.
+func (l *Library) seedThreat(name, description string) {
+	id, ok := lookup(l.Threats, name, func(t Threat) string { return t.Name })
+	if !ok {
+		return
+	}
+	t := l.Threats[id]
+	t.Seeded = true
+	if t.Description == "" {
+		t.Description = description
+	}
+	l.Threats[id] = t
+}
+
+// containsPath reports whether paths already holds path.
+func containsPath(paths [][]string, path []string) bool {
+	for _, p := range paths {
+		if strings.Join(p, ":") == strings.Join(path, ":") {
+			return true
+		}
+	}
+
+	return false
+}
+
 func parse_name(id string) (string, string) {
 	return id, id
 }
 
-func (l *Library) SaveFiles() {
-	os.Mkdir("threatmodel", 0o700)
+// Sort orders the annotations of the threat model by file, line and annotation, so that the saved files do not
+// depend on the order the source files were parsed in.
+func (l *Library) Sort() {
+	tm := &l.ThreatModel
+	sort.SliceStable(tm.Mitigations, func(i, j int) bool { return less(tm.Mitigations[i].Source, tm.Mitigations[j].Source) })
+	sort.SliceStable(tm.Exposures, func(i, j int) bool { return less(tm.Exposures[i].Source, tm.Exposures[j].Source) })
+	sort.SliceStable(tm.Transfers, func(i, j int) bool { return less(tm.Transfers[i].Source, tm.Transfers[j].Source) })
+	sort.SliceStable(tm.Acceptances, func(i, j int) bool { return less(tm.Acceptances[i].Source, tm.Acceptances[j].Source) })
+	sort.SliceStable(tm.Connections, func(i, j int) bool { return less(tm.Connections[i].Source, tm.Connections[j].Source) })
+	sort.SliceStable(tm.Reviews, func(i, j int) bool { return less(tm.Reviews[i].Source, tm.Reviews[j].Source) })
+	sort.SliceStable(tm.Tests, func(i, j int) bool { return less(tm.Tests[i].Source, tm.Tests[j].Source) })
+}
 
-	file, _ := json.MarshalIndent(l.Controls, "", " ")
-	os.WriteFile("threatmodel/controls.json", file, 0o666)
+// less orders sources by file, line and annotation.
+func less(a, b Source) bool {
+	if a.Filename != b.Filename {
+		return a.Filename < b.Filename
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
 
-	file, _ = json.MarshalIndent(l.Threats, "", " ")
-	os.WriteFile("threatmodel/threats.json", file, 0o666)
+	return a.Annotation < b.Annotation
+}
+
+// SaveFiles writes the library and the threat model to the threat model directory. The output is canonical: the
+// annotations are sorted, the keys of the JSON objects too, empty lists are written as [] and each file ends with a
+// newline.
+func (l *Library) SaveFiles(dir string) error {
+	l.Sort()
+
+	tm := &l.ThreatModel
+	if tm.Mitigations == nil {
+		tm.Mitigations = []Mitigate{}
+	}
+	if tm.Exposures == nil {
+		tm.Exposures = []Exposure{}
+	}
+	if tm.Transfers == nil {
+		tm.Transfers = []Transfer{}
+	}
+	if tm.Acceptances == nil {
+		tm.Acceptances = []Acceptance{}
+	}
+	if tm.Connections == nil {
+		tm.Connections = []Connection{}
+	}
+	if tm.Reviews == nil {
+		tm.Reviews = []Review{}
+	}
+	if tm.Tests == nil {
+		tm.Tests = []Test{}
+	}
+	if tm.Scope.Paths == nil {
+		tm.Scope.Paths = []string{}
+	}
+	if tm.Scope.Languages == nil {
+		tm.Scope.Languages = map[string]int{}
+	}
 
-	file, _ = json.MarshalIndent(l.Components, "", " ")
-	os.WriteFile("threatmodel/components.json", file, 0o666)
+	if err := l.SaveLibraryFiles(dir); err != nil {
+		return err
+	}
 
-	file, _ = json.MarshalIndent(l.ThreatModel, "", " ")
-	os.WriteFile("threatmodel/threatModel.json", file, 0o666)
+	return writeJSON(filepath.Join(dir, "threatModel.json"), l.ThreatModel)
 }
 
-func (l *Library) ReadFiles() error {
-	controlsFile, err := os.ReadFile("threatmodel/controls.json")
-	if err != nil {
-		return eris.Wrap(err, "failed to read threatmodel/controls.json")
+// SaveLibraryFiles writes the threats, controls and components of the library to the threat model directory.
+func (l *Library) SaveLibraryFiles(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return eris.Wrapf(err, "failed to create %s", dir)
+	}
+
+	for _, f := range []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "controls.json", v: l.Controls},
+		{name: "threats.json", v: l.Threats},
+		{name: "components.json", v: l.Components},
+	} {
+		if err := writeJSON(filepath.Join(dir, f.name), f.v); err != nil {
+			return err
+		}
 	}
 
-	threatsFile, err := os.ReadFile("threatmodel/threats.json")
+	return nil
+}
+
+// writeJSON writes v as indented JSON followed by a newline.
+func writeJSON(filename string, v interface{}) error {
+	file, err := json.MarshalIndent(v, "", " ")
 	if err != nil {
-		return eris.Wrap(err, "failed to read threatmodel/threats.json")
+		return eris.Wrapf(err, "failed to encode %s", filename)
 	}
 
-	componentsFile, err := os.ReadFile("threatmodel/components.json")
-	if err != nil {
-		return eris.Wrap(err, "failed to read threatmodel/components.json")
+	if err := os.WriteFile(filename, append(file, '\n'), 0o666); err != nil {
+		return eris.Wrapf(err, "failed to write %s", filename)
 	}
 
-	threatModelFile, err := os.ReadFile("threatmodel/threatModel.json")
-	if err != nil {
-		return eris.Wrap(err, "failed to read threatmodel/threatModel.json")
+	return nil
+}
+
+// ReadFiles reads the library and the threat model from the threat model directory.
+func (l *Library) ReadFiles(dir string) error {
+	if err := l.readFiles(dir, false); err != nil {
+		return err
 	}
 
-	err = json.Unmarshal(controlsFile, &l.Controls)
-	if err != nil {
-		return eris.Wrap(err, "failed to understand threatmodel/controls.json")
+	return readJSON(filepath.Join(dir, "threatModel.json"), &l.ThreatModel)
+}
+
+// ReadLibraryFiles reads the threats, controls and components of the library from the threat model directory, if
+// they exist, so that an import adds to them.
+func (l *Library) ReadLibraryFiles(dir string) error {
+	return l.readFiles(dir, true)
+}
+
+// ReadSeeds reads the seeded threats, controls and components of the library files of the threat model directory, if
+// they exist, so that run keeps the entries imported or written by hand and rebuilds the others from the annotations.
+func (l *Library) ReadSeeds(dir string) error {
+	if err := l.readFiles(dir, true); err != nil {
+		return err
 	}
 
-	err = json.Unmarshal(threatsFile, &l.Threats)
-	if err != nil {
-		return eris.Wrap(err, "failed to understand threats/controls.json")
+	for id, c := range l.Components {
+		if !c.Seeded {
+			delete(l.Components, id)
+		}
+	}
+	for id, c := range l.Controls {
+		if !c.Seeded {
+			delete(l.Controls, id)
+		}
+	}
+	for id, t := range l.Threats {
+		if !t.Seeded {
+			delete(l.Threats, id)
+		}
 	}
 
-	err = json.Unmarshal(componentsFile, &l.Components)
-	if err != nil {
-		return eris.Wrap(err, "failed to understand threatmodel/components.json")
+	return nil
+}
+
+// readFiles reads the library files, skipping the missing ones when optional. Libraries wrapped under their name, as
+// threatspec writes them, are read too, so that a threatspec project is imported in place.
+func (l *Library) readFiles(dir string, optional bool) error {
+	for _, f := range []struct {
+		name string
+		key  string
+		v    interface{}
+	}{
+		{name: "controls.json", key: "controls", v: &l.Controls},
+		{name: "threats.json", key: "threats", v: &l.Threats},
+		{name: "components.json", key: "components", v: &l.Components},
+	} {
+		filename := filepath.Join(dir, f.name)
+		if _, err := os.Stat(filename); optional && os.IsNotExist(err) {
+			continue
+		}
+
+		file, err := os.ReadFile(filename)
+		if err != nil {
+			return eris.Wrapf(err, "failed to read %s", filename)
+		}
+
+		if err := unwrapThreatspec(file, f.key, f.v); err != nil {
+			return eris.Wrapf(err, "failed to understand %s, famed-annotated validate locates the errors", filename)
+		}
 	}
 
-	err = json.Unmarshal(threatModelFile, &l.ThreatModel)
+	return nil
+}
+
+// readJSON reads v from a JSON file.
+func readJSON(filename string, v interface{}) error {
+	file, err := os.ReadFile(filename)
 	if err != nil {
-		return eris.Wrap(err, "failed to understand threatmodel/threatModel.json")
+		return eris.Wrapf(err, "failed to read %s", filename)
+	}
+
+	if err := json.Unmarshal(file, v); err != nil {
+		return eris.Wrapf(err, "failed to understand %s, famed-annotated validate locates the errors", filename)
 	}
 
 	return nil
@@ -0,0 +1,152 @@
+package library
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newLibrary returns an empty library, ready to add entries to.
+func newLibrary() *Library {
+	return &Library{
+		Components: map[string]Component{},
+		Controls:   map[string]Control{},
+		Threats:    map[string]Threat{},
+	}
+}
+
+// writeFile writes a file in a directory and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	filename := filepath.Join(dir, name)
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return filename
+}
+
+func TestSaveFiles(t *testing.T) {
+	comments := []struct {
+		comment string
+		source  Source
+	}{
+		{comment: "@mitigates WebApp:Web against XSS with escaping", source: Source{Filename: "web.go", Line: 10}},
+		{comment: "@exposes WebApp:DB to SQL injection with raw queries", source: Source{Filename: "db.go", Line: 20}},
+		{comment: "@mitigates WebApp:DB against SQL injection with prepared statements", source: Source{Filename: "db.go", Line: 5}},
+	}
+
+	tests := []struct {
+		name  string
+		order []int
+	}{
+		{name: "empty"},
+		{name: "parsed in order", order: []int{0, 1, 2}},
+		{name: "parsed in reverse order", order: []int{2, 1, 0}},
+	}
+
+	saved := map[string]string{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLibrary()
+			for _, i := range tt.order {
+				l.Parse(comments[i].comment, comments[i].source)
+			}
+
+			dir := filepath.Join(t.TempDir(), "threatmodel")
+			if err := l.SaveFiles(dir); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, name := range []string{"controls.json", "threats.json", "components.json", "threatModel.json"} {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if bytes.Contains(data, []byte("null")) || !bytes.HasSuffix(data, []byte("}\n")) {
+					t.Errorf("SaveFiles() wrote %s = %s, want no null and a final newline", name, data)
+				}
+				if len(tt.order) == 0 {
+					continue
+				}
+				if previous, ok := saved[name]; ok && previous != string(data) {
+					t.Errorf("SaveFiles() wrote %s = %s, want the same as %s", name, data, previous)
+				}
+				saved[name] = string(data)
+			}
+		})
+	}
+}
+
+func TestSaveFilesError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "threatmodel")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := newLibrary().SaveFiles(file); err == nil {
+		t.Error("SaveFiles() into a file succeeded, want an error")
+	}
+}
+
+func TestReadSeeds(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "threats.json", `{
+		"#sqli": {"id": "#sqli", "name": "SQL injection", "seeded": true},
+		"XSS": {"id": "XSS", "name": "XSS"}
+	}`)
+
+	l := newLibrary()
+	if err := l.ReadSeeds(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := l.Threats["#sqli"]; !ok || len(l.Threats) != 1 {
+		t.Errorf("ReadSeeds() read %v, want only #sqli", sortedKeys(l.Threats))
+	}
+}
+
+func TestAddThreat(t *testing.T) {
+	tests := []struct {
+		name      string
+		reference string
+		want      string
+		threats   int
+	}{
+		{name: "by id", reference: "#cwe-89", want: "SQL injection", threats: 1},
+		{name: "by name", reference: "SQL injection", want: "SQL injection", threats: 1},
+		{name: "new", reference: "XSS", want: "XSS", threats: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLibrary()
+			l.Threats["#cwe-89"] = Threat{Id: "#cwe-89", Name: "SQL injection"}
+
+			if got := l.addThreat(&Threat{Name: tt.reference}); got != tt.want {
+				t.Errorf("addThreat() = %q, want %q", got, tt.want)
+			}
+			if len(l.Threats) != tt.threats {
+				t.Errorf("addThreat() left %d threats, want %d", len(l.Threats), tt.threats)
+			}
+		})
+	}
+}
+
+func TestAddComponent(t *testing.T) {
+	l := newLibrary()
+	l.Components["#web"] = Component{Id: "#web", Name: "WebApp:Web", Paths: [][]string{{"WebApp"}}, Seeded: true}
+
+	for _, reference := range []string{"#web", "WebApp:Web"} {
+		if got := l.addComponent(&Component{Name: reference}); got != "WebApp:Web" {
+			t.Errorf("addComponent(%q) = %q, want WebApp:Web", reference, got)
+		}
+	}
+	if len(l.Components) != 1 {
+		t.Fatalf("addComponent() left %d components, want 1", len(l.Components))
+	}
+	if c := l.Components["#web"]; !c.Seeded || len(c.Paths) != 1 {
+		t.Errorf("addComponent() changed the component to %+v", c)
+	}
+}
@@ -0,0 +1,163 @@
+package library
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// OTMVersion is the version of the Open Threat Model format that is imported and exported.
+const OTMVersion = "0.2.0"
+
+// OTMDefaultZone is the trust zone of the exported components outside of any trust boundary, which stay outside of
+// any when imported back.
+const OTMDefaultZone = "default"
+
+// Subset of the Open Threat Model (OTM) format used to exchange models with other threat modeling tools.
+type (
+	OTM struct {
+		OTMVersion  string          `json:"otmVersion"`
+		Project     OTMProject      `json:"project"`
+		TrustZones  []OTMTrustZone  `json:"trustZones"`
+		Components  []OTMComponent  `json:"components"`
+		Dataflows   []OTMDataflow   `json:"dataflows"`
+		Threats     []OTMThreat     `json:"threats"`
+		Mitigations []OTMMitigation `json:"mitigations"`
+	}
+	OTMProject struct {
+		Name        string `json:"name"`
+		ID          string `json:"id"`
+		Description string `json:"description,omitempty"`
+	}
+	OTMTrustZone struct {
+		ID          string       `json:"id"`
+		Name        string       `json:"name"`
+		Description string       `json:"description,omitempty"`
+		Risk        OTMTrustRisk `json:"risk"`
+		Parent      *OTMParent   `json:"parent,omitempty"`
+	}
+	OTMTrustRisk struct {
+		TrustRating int `json:"trustRating"`
+	}
+	// OTMParent is the trust zone or the component an element is part of.
+	OTMParent struct {
+		TrustZone string `json:"trustZone,omitempty"`
+		Component string `json:"component,omitempty"`
+	}
+	OTMComponent struct {
+		ID          string              `json:"id"`
+		Name        string              `json:"name"`
+		Type        string              `json:"type"`
+		Description string              `json:"description,omitempty"`
+		Parent      OTMParent           `json:"parent"`
+		Threats     []OTMThreatInstance `json:"threats,omitempty"`
+	}
+	// OTMThreatInstance relates a threat to the component it applies to, with the mitigations implemented against it.
+	OTMThreatInstance struct {
+		Threat      string                  `json:"threat"`
+		State       string                  `json:"state"`
+		Mitigations []OTMMitigationInstance `json:"mitigations,omitempty"`
+	}
+	OTMMitigationInstance struct {
+		Mitigation string `json:"mitigation"`
+		State      string `json:"state"`
+	}
+	OTMDataflow struct {
+		ID            string `json:"id"`
+		Name          string `json:"name,omitempty"`
+		Bidirectional bool   `json:"bidirectional"`
+		Source        string `json:"source"`
+		Destination   string `json:"destination"`
+	}
+	OTMThreat struct {
+		ID          string        `json:"id"`
+		Name        string        `json:"name"`
+		Description string        `json:"description,omitempty"`
+		Categories  []string      `json:"categories"`
+		Risk        OTMThreatRisk `json:"risk"`
+	}
+	OTMThreatRisk struct {
+		Likelihood int `json:"likelihood"`
+		Impact     int `json:"impact"`
+	}
+	OTMMitigation struct {
+		ID            string `json:"id"`
+		Name          string `json:"name"`
+		Description   string `json:"description,omitempty"`
+		RiskReduction int    `json:"riskReduction"`
+	}
+)
+
+// ImportOTM adds the threats, mitigations and components of an Open Threat Model file to the library, as threats,
+// controls and components. Components are named after the trust zones and the components they are nested in,
+// separated by colons, like in annotations.
+func (l *Library) ImportOTM(filename string) error {
+	file, err := os.ReadFile(filename)
+	if err != nil {
+		return eris.Wrapf(err, "failed to read %s", filename)
+	}
+
+	var otm OTM
+	if err := json.Unmarshal(file, &otm); err != nil {
+		return eris.Wrapf(err, "failed to understand %s", filename)
+	}
+
+	for _, t := range otm.Threats {
+		l.addThreat(&Threat{Name: t.Name})
+		l.seedThreat(t.Name, t.Description)
+	}
+
+	for _, m := range otm.Mitigations {
+		l.addControl(&Control{Name: m.Name})
+		l.seedControl(m.Name, m.Description)
+	}
+
+	zones := map[string]OTMTrustZone{}
+	for _, z := range otm.TrustZones {
+		zones[z.ID] = z
+	}
+	components := map[string]OTMComponent{}
+	for _, c := range otm.Components {
+		components[c.ID] = c
+	}
+
+	for _, c := range otm.Components {
+		name := strings.Join(append(otmPath(c.Parent, zones, components, map[string]bool{c.ID: true}), c.Name), ":")
+		l.addComponent(&Component{Name: name})
+		l.seedComponent(name, c.Description)
+	}
+
+	return nil
+}
+
+// otmPath returns the names of the trust zones and components a parent is nested in, outermost first. Seen guards
+// against parents nested in themselves.
+func otmPath(parent OTMParent, zones map[string]OTMTrustZone, components map[string]OTMComponent, seen map[string]bool) []string {
+	switch {
+	case parent.Component != "" && !seen[parent.Component]:
+		c, ok := components[parent.Component]
+		if !ok {
+			return nil
+		}
+		seen[c.ID] = true
+
+		return append(otmPath(c.Parent, zones, components, seen), c.Name)
+	case parent.TrustZone != "" && !seen[parent.TrustZone]:
+		z, ok := zones[parent.TrustZone]
+		if !ok || z.ID == OTMDefaultZone {
+			return nil
+		}
+		seen[z.ID] = true
+
+		var path []string
+		if z.Parent != nil {
+			path = otmPath(*z.Parent, zones, components, seen)
+		}
+
+		return append(path, z.Name)
+	default:
+		return nil
+	}
+}
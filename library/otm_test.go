@@ -0,0 +1,48 @@
+package library
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImportOTM(t *testing.T) {
+	filename := writeFile(t, t.TempDir(), "model.otm", `{
+		"otmVersion": "0.2.0",
+		"project": {"name": "shop", "id": "shop"},
+		"trustZones": [
+			{"id": "internet", "name": "Internet", "risk": {"trustRating": 10}},
+			{"id": "dmz", "name": "DMZ", "risk": {"trustRating": 50}, "parent": {"trustZone": "internet"}},
+			{"id": "default", "name": "Default", "risk": {"trustRating": 50}}
+		],
+		"components": [
+			{"id": "web", "name": "Web", "type": "web-service", "description": "The shop.", "parent": {"trustZone": "dmz"}},
+			{"id": "db", "name": "DB", "type": "database", "parent": {"component": "web"}},
+			{"id": "orphan", "name": "Orphan", "type": "generic", "parent": {"trustZone": "unknown"}},
+			{"id": "cache", "name": "Cache", "type": "generic", "parent": {"trustZone": "default"}}
+		],
+		"threats": [{"id": "t1", "name": "SQL injection", "description": "Injected queries.", "categories": [], "risk": {"likelihood": 50, "impact": 50}}],
+		"mitigations": [{"id": "m1", "name": "Prepared statements", "riskReduction": 50}]
+	}`)
+
+	l := newLibrary()
+	l.Threats["#sqli"] = Threat{Id: "#sqli", Name: "SQL injection"}
+	if err := l.ImportOTM(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := sortedKeys(l.Components), []string{"Cache", "Internet:DMZ:Web", "Internet:DMZ:Web:DB", "Orphan"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportOTM() components = %v, want %v", got, want)
+	}
+	if c := l.Components["Internet:DMZ:Web"]; c.Description != "The shop." || !c.Seeded || !reflect.DeepEqual(c.Paths, [][]string{{"Internet", "DMZ"}}) {
+		t.Errorf("ImportOTM() component = %+v", c)
+	}
+	if got := sortedKeys(l.Threats); !reflect.DeepEqual(got, []string{"#sqli"}) {
+		t.Errorf("ImportOTM() threats = %v, want the existing #sqli", got)
+	}
+	if threat := l.Threats["#sqli"]; threat.Description != "Injected queries." || !threat.Seeded {
+		t.Errorf("ImportOTM() threat = %+v", threat)
+	}
+	if c, ok := l.Controls["Prepared statements"]; !ok || !c.Seeded {
+		t.Errorf("ImportOTM() control = %+v", c)
+	}
+}
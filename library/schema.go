@@ -0,0 +1,327 @@
+package library
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rotisserie/eris"
+)
+
+//go:embed schemas/*.schema.json
+var schemas embed.FS
+
+// Files are the files of the threat model directory, in the order they are validated.
+var Files = []string{"threats.json", "controls.json", "components.json", "threatModel.json"}
+
+// ValidationError locates what is wrong in a file of the threat model directory with a JSON pointer.
+type ValidationError struct {
+	File    string
+	Pointer string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.File + "#" + e.Pointer + ": " + e.Message
+}
+
+// Schema returns the JSON Schema of a file of the threat model directory.
+func Schema(file string) ([]byte, error) {
+	schema, err := schemas.ReadFile("schemas/" + SchemaName(file))
+	if err != nil {
+		return nil, eris.Errorf("no schema for %s, expected one of: %s", file, strings.Join(Files, ", "))
+	}
+
+	return schema, nil
+}
+
+// SchemaName returns the name of the schema of a file, like threats.schema.json.
+func SchemaName(file string) string {
+	return strings.TrimSuffix(file, ".json") + ".schema.json"
+}
+
+// Validate checks the files of the threat model directory against their schema, that the library entries are
+// indexed by their id, and that every component, threat and control the threat model references is in the library.
+func Validate(dir string) ([]ValidationError, error) {
+	var errs []ValidationError
+	for _, file := range Files {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to read %s", filepath.Join(dir, file))
+		}
+
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+		var document interface{}
+		if err := d.Decode(&document); err != nil {
+			errs = append(errs, ValidationError{File: file, Message: "invalid JSON: " + err.Error()})
+			continue
+		}
+
+		raw, _ := Schema(file)
+		var schema map[string]interface{}
+		if err := json.Unmarshal(raw, &schema); err != nil {
+			return nil, eris.Wrapf(err, "failed to understand the schema of %s", file)
+		}
+
+		v := validator{file: file, root: schema}
+		v.validate(schema, document, "")
+		errs = append(errs, v.errs...)
+	}
+
+	// The references are only checked between files which match their schema.
+	if len(errs) > 0 {
+		return errs, nil
+	}
+
+	l := Library{}
+	if err := l.ReadFiles(dir); err != nil {
+		return nil, err
+	}
+
+	return l.integrity(), nil
+}
+
+// integrity checks that the library entries are indexed by their id and that the threat model only references
+// entries of the library.
+func (l *Library) integrity() []ValidationError {
+	var errs []ValidationError
+	for _, key := range sortedKeys(l.Threats) {
+		if id := l.Threats[key].Id; id != key {
+			errs = append(errs, ValidationError{File: "threats.json", Pointer: pointer("", key, "id"), Message: fmt.Sprintf("id %q does not match its key", id)})
+		}
+	}
+	for _, key := range sortedKeys(l.Controls) {
+		if id := l.Controls[key].Id; id != key {
+			errs = append(errs, ValidationError{File: "controls.json", Pointer: pointer("", key, "id"), Message: fmt.Sprintf("id %q does not match its key", id)})
+		}
+	}
+	for _, key := range sortedKeys(l.Components) {
+		if id := l.Components[key].Id; id != key {
+			errs = append(errs, ValidationError{File: "components.json", Pointer: pointer("", key, "id"), Message: fmt.Sprintf("id %q does not match its key", id)})
+		}
+	}
+
+	type reference struct {
+		pointer, kind, name string
+	}
+	var references []reference
+	add := func(records string, i int, kind, field, name string) {
+		references = append(references, reference{pointer: pointer("", records, fmt.Sprint(i), field), kind: kind, name: name})
+	}
+
+	tm := l.ThreatModel
+	for i, m := range tm.Mitigations {
+		add("mitigations", i, "control", "control", m.Control)
+		add("mitigations", i, "threat", "threat", m.Threat)
+		add("mitigations", i, "component", "component", m.Component)
+	}
+	for i, e := range tm.Exposures {
+		add("exposures", i, "threat", "threat", e.Threat)
+		add("exposures", i, "component", "component", e.Component)
+	}
+	for i, t := range tm.Transfers {
+		add("transfers", i, "threat", "threat", t.Threat)
+		add("transfers", i, "component", "source_component", t.SourceComponent)
+		add("transfers", i, "component", "destination_component", t.DestinationComponent)
+	}
+	for i, a := range tm.Acceptances {
+		add("acceptances", i, "threat", "threat", a.Threat)
+		add("acceptances", i, "component", "component", a.Component)
+	}
+	for i, c := range tm.Connections {
+		add("connections", i, "component", "source_component", c.SourceComponent)
+		add("connections", i, "component", "destination_component", c.DestinationComponent)
+	}
+	for i, r := range tm.Reviews {
+		add("reviews", i, "component", "component", r.Component)
+	}
+	for i, t := range tm.Tests {
+		add("tests", i, "component", "component", t.Component)
+		add("tests", i, "control", "control", t.Control)
+	}
+
+	for _, r := range references {
+		var ok bool
+		switch r.kind {
+		case "threat":
+			_, ok = l.FindThreat(r.name)
+		case "control":
+			_, ok = l.FindControl(r.name)
+		case "component":
+			_, ok = l.FindComponent(r.name)
+		}
+		if !ok {
+			errs = append(errs, ValidationError{
+				File:    "threatModel.json",
+				Pointer: r.pointer,
+				Message: fmt.Sprintf("%s %q is not in %ss.json", r.kind, r.name, r.kind),
+			})
+		}
+	}
+
+	return errs
+}
+
+// validator validates a document against the subset of JSON Schema used by the schemas of the threat model files:
+// $ref to $defs, type, enum, properties, required, additionalProperties, items, minLength and minimum.
+type validator struct {
+	file string
+	root map[string]interface{}
+	errs []ValidationError
+}
+
+func (v *validator) fail(at, format string, a ...interface{}) {
+	v.errs = append(v.errs, ValidationError{File: v.file, Pointer: at, Message: fmt.Sprintf(format, a...)})
+}
+
+func (v *validator) validate(schema map[string]interface{}, value interface{}, at string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, _ := v.root["$defs"].(map[string]interface{})[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+		v.validate(def, value, at)
+
+		return
+	}
+
+	if t, ok := schema["type"]; ok && !hasType(t, value) {
+		v.fail(at, "expected %s, got %s", typeNames(t), typeOf(value))
+
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || e == value
+		}
+		if !found {
+			v.fail(at, "expected one of %s, got %v", typeNames(enum), value)
+		}
+	}
+
+	switch value := value.(type) {
+	case string:
+		if min, ok := schema["minLength"].(float64); ok && utf8.RuneCountInString(value) < int(min) {
+			v.fail(at, "expected at least %d characters", int(min))
+		}
+	case json.Number:
+		if min, ok := schema["minimum"].(float64); ok {
+			if n, err := value.Float64(); err == nil && n < min {
+				v.fail(at, "expected at least %v, got %s", min, value)
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range value {
+				v.validate(items, item, pointer(at, fmt.Sprint(i)))
+			}
+		}
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, key := range required {
+				if _, ok := value[key.(string)]; !ok {
+					v.fail(at, "missing required property %q", key)
+				}
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]interface{})
+		for _, key := range sortedKeys(value) {
+			if property, ok := properties[key].(map[string]interface{}); ok {
+				v.validate(property, value[key], pointer(at, key))
+				continue
+			}
+
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					v.fail(pointer(at, key), "unexpected property %q", key)
+				}
+			case map[string]interface{}:
+				v.validate(additional, value[key], pointer(at, key))
+			}
+		}
+	}
+}
+
+// hasType reports whether the value has the JSON Schema type, or one of the types.
+func hasType(t interface{}, value interface{}) bool {
+	if types, ok := t.([]interface{}); ok {
+		for _, t := range types {
+			if hasType(t, value) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	actual := typeOf(value)
+	if t == "number" && actual == "integer" {
+		return true
+	}
+
+	return t == actual
+}
+
+// typeOf returns the JSON Schema type of a decoded value.
+func typeOf(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return "integer"
+		}
+
+		return "number"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// typeNames returns a type or a list of types for messages.
+func typeNames(t interface{}) string {
+	if types, ok := t.([]interface{}); ok {
+		names := make([]string, len(types))
+		for i, t := range types {
+			names[i] = fmt.Sprint(t)
+		}
+
+		return strings.Join(names, " or ")
+	}
+
+	return fmt.Sprint(t)
+}
+
+// pointer appends escaped reference tokens to a JSON pointer.
+func pointer(at string, tokens ...string) string {
+	for _, token := range tokens {
+		at += "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+	}
+
+	return at
+}
+
+// sortedKeys returns the keys of a map, sorted.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
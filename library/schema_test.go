@@ -0,0 +1,183 @@
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidator(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"line": {"type": "integer", "minimum": 1},
+			"direction": {"enum": ["to", "from", "with"]},
+			"paths": {"type": "array", "items": {"type": "array", "items": {"type": "string"}}},
+			"source": {"$ref": "#/$defs/source"},
+			"custom": {"type": "object"}
+		},
+		"additionalProperties": false,
+		"$defs": {
+			"source": {"type": ["object", "null"], "required": ["filename"], "properties": {"filename": {"type": "string"}}}
+		}
+	}`
+
+	tests := []struct {
+		name     string
+		document string
+		want     []ValidationError
+	}{
+		{
+			name:     "valid",
+			document: `{"name": "SQL injection", "line": 3, "direction": "to", "paths": [["WebApp"]], "source": {"filename": "main.go"}, "custom": {}}`,
+		},
+		{
+			name:     "null allowed by a list of types",
+			document: `{"name": "SQL injection", "source": null}`,
+		},
+		{
+			name:     "wrong type",
+			document: `{"name": 1}`,
+			want:     []ValidationError{{File: "test.json", Pointer: "/name", Message: "expected string, got integer"}},
+		},
+		{
+			name:     "number is not an integer",
+			document: `{"name": "x", "line": 1.5}`,
+			want:     []ValidationError{{File: "test.json", Pointer: "/line", Message: "expected integer, got number"}},
+		},
+		{
+			name:     "missing required property",
+			document: `{}`,
+			want:     []ValidationError{{File: "test.json", Pointer: "", Message: `missing required property "name"`}},
+		},
+		{
+			name:     "unexpected property",
+			document: `{"name": "x", "extra": true}`,
+			want:     []ValidationError{{File: "test.json", Pointer: "/extra", Message: `unexpected property "extra"`}},
+		},
+		{
+			name:     "too short",
+			document: `{"name": ""}`,
+			want:     []ValidationError{{File: "test.json", Pointer: "/name", Message: "expected at least 1 characters"}},
+		},
+		{
+			name:     "below minimum",
+			document: `{"name": "x", "line": 0}`,
+			want:     []ValidationError{{File: "test.json", Pointer: "/line", Message: "expected at least 1, got 0"}},
+		},
+		{
+			name:     "not in enum",
+			document: `{"name": "x", "direction": "up"}`,
+			want:     []ValidationError{{File: "test.json", Pointer: "/direction", Message: "expected one of to or from or with, got up"}},
+		},
+		{
+			name:     "nested items",
+			document: `{"name": "x", "paths": [["WebApp", 2]]}`,
+			want:     []ValidationError{{File: "test.json", Pointer: "/paths/0/1", Message: "expected string, got integer"}},
+		},
+		{
+			name:     "reference",
+			document: `{"name": "x", "source": {}}`,
+			want:     []ValidationError{{File: "test.json", Pointer: "/source", Message: `missing required property "filename"`}},
+		},
+		{
+			name:     "escaped pointer",
+			document: `{"name": "x", "a/b~c": 1}`,
+			want:     []ValidationError{{File: "test.json", Pointer: "/a~1b~0c", Message: `unexpected property "a/b~c"`}},
+		},
+	}
+
+	var root map[string]interface{}
+	if err := json.Unmarshal([]byte(schema), &root); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := json.NewDecoder(bytes.NewReader([]byte(tt.document)))
+			d.UseNumber()
+			var document interface{}
+			if err := d.Decode(&document); err != nil {
+				t.Fatal(err)
+			}
+
+			v := validator{file: "test.json", root: root}
+			v.validate(root, document, "")
+			if !reflect.DeepEqual(v.errs, tt.want) {
+				t.Errorf("validate() = %v, want %v", v.errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	library := map[string]string{
+		"threats.json":    `{"#sqli": {"id": "#sqli", "run_id": "", "name": "SQL injection", "description": "", "custom": {}}}`,
+		"controls.json":   `{"Prepared statements": {"id": "Prepared statements", "run_id": "", "name": "Prepared statements", "description": "", "custom": {}}}`,
+		"components.json": `{"WebApp:Web": {"id": "WebApp:Web", "run_id": "", "name": "WebApp:Web", "description": "", "paths": [["WebApp"]], "custom": {}}}`,
+	}
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		threatModel string
+		want        []ValidationError
+	}{
+		{
+			name:        "valid",
+			threatModel: `{"mitigations": [{"control": "Prepared statements", "threat": "SQL injection", "component": "WebApp:Web", "description": "", "custom": {}, "source": {"filename": "main.go", "line": 3}}]}`,
+		},
+		{
+			name:        "unknown reference",
+			threatModel: `{"exposures": [{"threat": "XSS", "component": "WebApp:Web", "details": "", "description": "", "custom": {}, "source": {"filename": "main.go", "line": 3}}]}`,
+			want: []ValidationError{
+				{File: "threatModel.json", Pointer: "/exposures/0/threat", Message: `threat "XSS" is not in threats.json`},
+			},
+		},
+		{
+			name:        "id not matching its key",
+			files:       map[string]string{"threats.json": `{"#xss": {"id": "#sqli", "run_id": "", "name": "SQL injection", "description": "", "custom": {}}}`},
+			threatModel: `{}`,
+			want: []ValidationError{
+				{File: "threats.json", Pointer: "/#xss/id", Message: `id "#sqli" does not match its key`},
+			},
+		},
+		{
+			name:        "invalid JSON",
+			threatModel: `{`,
+			want: []ValidationError{
+				{File: "threatModel.json", Message: "invalid JSON: unexpected EOF"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]string{"threatModel.json": tt.threatModel}
+			for name, content := range library {
+				files[name] = content
+			}
+			for name, content := range tt.files {
+				files[name] = content
+			}
+			for name, content := range files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			errs, err := Validate(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(errs, tt.want) {
+				t.Errorf("Validate() = %v, want %v", errs, tt.want)
+			}
+		})
+	}
+}
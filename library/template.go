@@ -0,0 +1,79 @@
+package library
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/rotisserie/eris"
+)
+
+// ParseTemplate adds to the library the annotations found in the comments of a text/template or
+// html/template file. Each annotation is linked to the define block it appears in.
+func (l *Library) ParseTemplate(filename string, data []byte) error {
+	text := string(data)
+
+	t := parse.New(filename)
+	t.Mode = parse.ParseComments | parse.SkipFuncCheck
+
+	trees := map[string]*parse.Tree{}
+	if _, err := t.Parse(text, "", "", trees); err != nil {
+		return eris.Wrapf(err, "failed to parse %s", filename)
+	}
+
+	names := make([]string, 0, len(trees))
+	for name := range trees {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		tree := trees[name]
+		if tree.Root == nil {
+			continue
+		}
+
+		code := ""
+		if name != filename {
+			code = snippet(fmt.Sprintf("{{define %q}}%s{{end}}", name, tree.Root.String()))
+		}
+
+		for _, comment := range comments(tree.Root) {
+			l.Parse(commentText(comment.Text), Source{
+				Code:     code,
+				Filename: filename,
+				Line:     1 + strings.Count(text[:comment.Pos], "\n"),
+			})
+		}
+	}
+
+	return nil
+}
+
+// comments returns every comment of a template node, including the ones nested in actions.
+func comments(node parse.Node) []*parse.CommentNode {
+	switch n := node.(type) {
+	case *parse.CommentNode:
+		return []*parse.CommentNode{n}
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+
+		var found []*parse.CommentNode
+		for _, child := range n.Nodes {
+			found = append(found, comments(child)...)
+		}
+
+		return found
+	case *parse.IfNode:
+		return append(comments(n.List), comments(n.ElseList)...)
+	case *parse.RangeNode:
+		return append(comments(n.List), comments(n.ElseList)...)
+	case *parse.WithNode:
+		return append(comments(n.List), comments(n.ElseList)...)
+	default:
+		return nil
+	}
+}
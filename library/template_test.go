@@ -0,0 +1,90 @@
+package library
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		line     int
+		code     string
+		wantErr  bool
+	}{
+		{
+			name:     "top level",
+			template: "<p>\n{{/* @exposes WebApp:Web to XSS with raw html */}}\n</p>",
+			line:     2,
+		},
+		{
+			name:     "define block",
+			template: "{{define \"user\"}}\n{{/* @exposes WebApp:Web to XSS with raw html */}}{{.Name}}\n{{end}}",
+			line:     2,
+			code:     "{{define \"user\"}}\n{{/* @exposes WebApp:Web to XSS with raw html */}}{{.Name}}\n{{end}}",
+		},
+		{
+			name:     "nested in an action",
+			template: "{{if .Admin}}\n{{range .Users}}\n{{- /* @exposes WebApp:Web to XSS with raw html */ -}}\n{{end}}{{end}}",
+			line:     3,
+		},
+		{
+			name:     "unknown functions",
+			template: "{{/* @exposes WebApp:Web to XSS with raw html */}}{{markdown .Body}}",
+			line:     1,
+		},
+		{
+			name:     "invalid",
+			template: "{{if}}",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLibrary()
+			err := l.ParseTemplate("user.html.tmpl", []byte(tt.template))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTemplate() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(l.ThreatModel.Exposures) != 1 {
+				t.Fatalf("ParseTemplate() found %d exposures, want 1", len(l.ThreatModel.Exposures))
+			}
+			e := l.ThreatModel.Exposures[0]
+			if e.Threat != "XSS" || e.Component != "WebApp:Web" || e.Details != "raw html" {
+				t.Errorf("ParseTemplate() exposure = %+v", e)
+			}
+			if e.Source.Filename != "user.html.tmpl" || e.Source.Line != tt.line || e.Source.Code != tt.code {
+				t.Errorf("ParseTemplate() source = %+v, want line %d and code %q", e.Source, tt.line, tt.code)
+			}
+		})
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("x", snippetLength+1)
+	lines := strings.Repeat("line\n", snippetLines+1)
+
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{name: "short", code: "func main() {}", want: "func main() {}"},
+		{name: "too long", code: long, want: long[:snippetLength] + "\n…"},
+		{name: "too many lines", code: lines, want: strings.TrimSuffix(strings.Repeat("line\n", snippetLines), "\n") + "\n…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snippet(tt.code); got != tt.want {
+				t.Errorf("snippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package library
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// threatspecDir is the directory threatspec writes its output files to.
+const threatspecDir = "threatmodel"
+
+var (
+	// threatspecNameRe matches a name with an explicit threatspec id, like SQL injection (#sqli).
+	threatspecNameRe = regexp.MustCompile(`^(.*?)\s*\((#[^()\s]+)\)$`)
+	threatspecIDRe   = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Output files of threatspec, whose libraries are indexed by id under the name of the library and whose threat model
+// entries reference the ids of the library entries.
+type (
+	ThreatspecThreats struct {
+		Threats map[string]Threat `json:"threats"`
+	}
+	ThreatspecControls struct {
+		Controls map[string]Control `json:"controls"`
+	}
+	ThreatspecComponents struct {
+		Components map[string]Component `json:"components"`
+	}
+)
+
+// ThreatspecID splits a name into the name and the id threatspec gives it: the id in parentheses at its end, or else
+// the name lowercased with underscores instead of other characters than letters and digits, prefixed with #.
+func ThreatspecID(name string) (string, string) {
+	if m := threatspecNameRe.FindStringSubmatch(name); m != nil {
+		return m[1], m[2]
+	}
+
+	return name, "#" + strings.Trim(threatspecIDRe.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
+
+// ThreatspecEntry returns the name and the threatspec id of a library entry: its own id when it is already one, like
+// #cwe-79, or else the id threatspec gives its name.
+func ThreatspecEntry(name, id string) (string, string) {
+	if strings.HasPrefix(id, "#") && id != name {
+		return name, id
+	}
+
+	return ThreatspecID(name)
+}
+
+// ImportThreatspec adds the library threatspec wrote in the threatmodel directory of a threatspec project to the
+// library, seeded. The entries keep their threatspec id, like #sqli, so that annotations reference them by id or by
+// name. The threat model is not imported: run rebuilds it from the annotations of the source code.
+func (l *Library) ImportThreatspec(dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, threatspecDir)); err == nil {
+		dir = filepath.Join(dir, threatspecDir)
+	}
+
+	var threats map[string]Threat
+	if err := readThreatspec(filepath.Join(dir, "threats.json"), "threats", &threats); err != nil {
+		return err
+	}
+	for id, t := range threats {
+		l.importThreat(Threat{Id: id, Name: t.Name, Description: t.Description, Custom: t.Custom})
+	}
+
+	var controls map[string]Control
+	if err := readThreatspec(filepath.Join(dir, "controls.json"), "controls", &controls); err != nil {
+		return err
+	}
+	for id, c := range controls {
+		l.importControl(Control{Id: id, Name: c.Name, Description: c.Description, Custom: c.Custom})
+	}
+
+	var components map[string]Component
+	if err := readThreatspec(filepath.Join(dir, "components.json"), "components", &components); err != nil {
+		return err
+	}
+	for id, c := range components {
+		l.importComponent(Component{Id: id, Name: c.Name, Description: c.Description, Custom: c.Custom})
+	}
+
+	return nil
+}
+
+// importControl adds an imported control to the library, seeded, merged into an existing control like importThreat.
+func (l *Library) importControl(control Control) {
+	control.Seeded = true
+	existing, ok := l.Controls[control.Id]
+	if !ok {
+		l.Controls[control.Id] = control
+
+		return
+	}
+
+	existing.Seeded = true
+	if existing.Name == existing.Id {
+		existing.Name = control.Name
+	}
+	if existing.Description == "" {
+		existing.Description = control.Description
+	}
+	existing.Custom = mergeCustom(existing.Custom, control.Custom)
+	l.Controls[control.Id] = existing
+}
+
+// importComponent adds an imported component to the library, seeded, merged into an existing component like
+// importThreat. The component is in the boundaries its name is nested in, like WebApp:Web.
+func (l *Library) importComponent(component Component) {
+	component.Seeded = true
+	existing, ok := l.Components[component.Id]
+	if !ok {
+		existing = component
+		existing.Custom = nil
+	}
+
+	existing.Seeded = true
+	if existing.Name == existing.Id {
+		existing.Name = component.Name
+	}
+	if existing.Description == "" {
+		existing.Description = component.Description
+	}
+	existing.Custom = mergeCustom(existing.Custom, component.Custom)
+	splittedName := strings.Split(existing.Name, ":")
+	if path := splittedName[:len(splittedName)-1]; !containsPath(existing.Paths, path) {
+		existing.Paths = append(existing.Paths, path)
+	}
+	l.Components[component.Id] = existing
+}
+
+// mergeCustom adds the imported custom values which are not set yet to the custom data of an entry.
+func mergeCustom(custom, imported Custom) Custom {
+	if custom == nil {
+		custom = Custom{}
+	}
+	for key, value := range imported {
+		if _, ok := custom[key]; !ok {
+			custom[key] = value
+		}
+	}
+
+	return custom
+}
+
+// readThreatspec reads a threatspec library file into index, from under the name of the library or from the root of
+// the file when the library is not wrapped.
+func readThreatspec(filename, key string, index interface{}) error {
+	file, err := os.ReadFile(filename)
+	if err != nil {
+		return eris.Wrapf(err, "failed to read %s", filename)
+	}
+
+	if err := unwrapThreatspec(file, key, index); err != nil {
+		return eris.Wrapf(err, "failed to understand %s", filename)
+	}
+
+	return nil
+}
+
+// unwrapThreatspec decodes a library, wrapped under the name of the library as threatspec writes it, or not. An entry
+// whose id is the name of the library is not taken for the wrapper.
+func unwrapThreatspec(data []byte, key string, index interface{}) error {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+	if wrapped, ok := keys[key]; ok && len(keys) == 1 {
+		// A failed decoding leaves the entries decoded so far, so the wrapped library is decoded on its own first.
+		unwrapped := reflect.New(reflect.TypeOf(index).Elem())
+		if err := json.Unmarshal(wrapped, unwrapped.Interface()); err == nil {
+			entries := reflect.ValueOf(index).Elem()
+			if entries.IsNil() {
+				entries.Set(reflect.MakeMap(entries.Type()))
+			}
+			for it := unwrapped.Elem().MapRange(); it.Next(); {
+				entries.SetMapIndex(it.Key(), it.Value())
+			}
+
+			return nil
+		}
+	}
+
+	return json.Unmarshal(data, index)
+}
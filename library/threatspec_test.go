@@ -0,0 +1,113 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestThreatspecID(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantName string
+		wantID   string
+	}{
+		{name: "SQL injection", wantName: "SQL injection", wantID: "#sql_injection"},
+		{name: "SQL injection (#sqli)", wantName: "SQL injection", wantID: "#sqli"},
+		{name: "WebApp:Web", wantName: "WebApp:Web", wantID: "#webapp_web"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if name, id := ThreatspecID(tt.name); name != tt.wantName || id != tt.wantID {
+				t.Errorf("ThreatspecID() = %q, %q, want %q, %q", name, id, tt.wantName, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestImportThreatspec(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name: "wrapped",
+			files: map[string]string{
+				"threats.json":    `{"threats": {"#sqli": {"name": "SQL injection", "description": "Injected queries."}}}`,
+				"controls.json":   `{"controls": {"#prepared": {"name": "Prepared statements", "description": ""}}}`,
+				"components.json": `{"components": {"#web": {"name": "WebApp:Web", "description": ""}}}`,
+			},
+		},
+		{
+			name: "unwrapped",
+			files: map[string]string{
+				"threats.json":    `{"#sqli": {"name": "SQL injection", "description": "Injected queries."}}`,
+				"controls.json":   `{"#prepared": {"name": "Prepared statements", "description": ""}}`,
+				"components.json": `{"#web": {"name": "WebApp:Web", "description": ""}}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := t.TempDir()
+			dir := filepath.Join(project, threatspecDir)
+			if err := os.Mkdir(dir, 0o700); err != nil {
+				t.Fatal(err)
+			}
+			for name, content := range tt.files {
+				writeFile(t, dir, name, content)
+			}
+			writeFile(t, dir, "threatmodel.json", `{"mitigations": {}}`)
+
+			l := newLibrary()
+			l.Threats["#sqli"] = Threat{Id: "#sqli", Name: "#sqli"}
+			if err := l.ImportThreatspec(project); err != nil {
+				t.Fatal(err)
+			}
+
+			want := Threat{Id: "#sqli", Name: "SQL injection", Description: "Injected queries.", Custom: Custom{}, Seeded: true}
+			if threat := l.Threats["#sqli"]; !reflect.DeepEqual(threat, want) || len(l.Threats) != 1 {
+				t.Errorf("ImportThreatspec() threats = %+v, want %+v", l.Threats, want)
+			}
+			if c := l.Controls["#prepared"]; c.Name != "Prepared statements" || !c.Seeded {
+				t.Errorf("ImportThreatspec() control = %+v", c)
+			}
+			if c := l.Components["#web"]; c.Name != "WebApp:Web" || !reflect.DeepEqual(c.Paths, [][]string{{"WebApp"}}) {
+				t.Errorf("ImportThreatspec() component = %+v", c)
+			}
+			if len(l.ThreatModel.Mitigations) > 0 {
+				t.Errorf("ImportThreatspec() imported the threat model")
+			}
+		})
+	}
+}
+
+func TestReadLibraryFilesThreatspec(t *testing.T) {
+	tests := []struct {
+		name    string
+		threats string
+		want    []string
+	}{
+		{name: "wrapped", threats: `{"threats": {"#sqli": {"id": "#sqli", "name": "SQL injection"}}}`, want: []string{"#sqli"}},
+		{name: "unwrapped", threats: `{"#sqli": {"id": "#sqli", "name": "SQL injection"}}`, want: []string{"#sqli"}},
+		{name: "entry named after the library", threats: `{"threats": {"id": "threats", "name": "threats"}}`, want: []string{"threats"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "threats.json", tt.threats)
+
+			l := newLibrary()
+			if err := l.ReadLibraryFiles(dir); err != nil {
+				t.Fatal(err)
+			}
+			if got := sortedKeys(l.Threats); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadLibraryFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
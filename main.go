@@ -16,9 +16,15 @@ type Globals struct {
 // Arguments are all the possible subcommands, arguments and flags that can be sent to the application.
 type Arguments struct {
 	Globals
-	Init   subcommand.Init   `cmd:"" help:"Initialise famed-annotated in the current directory."`
-	Report subcommand.Report `cmd:"" help:"Generate the famed-annotated threat model report."`
-	Run    subcommand.Run    `cmd:"" help:"Run famed-annotated against source code files."`
+	Export   subcommand.Export   `cmd:"" help:"Export the records of the threat model for spreadsheets and GRC platforms."`
+	Import   subcommand.Import   `cmd:"" help:"Seed the threat model library from a model drawn in another tool."`
+	Init     subcommand.Init     `cmd:"" help:"Initialise famed-annotated in the current directory."`
+	Library  subcommand.Library  `cmd:"" help:"Manage the threat model library."`
+	Matrix   subcommand.Matrix   `cmd:"" help:"Export the threat × component coverage matrix."`
+	Report   subcommand.Report   `cmd:"" help:"Generate the famed-annotated threat model report."`
+	Run      subcommand.Run      `cmd:"" help:"Run famed-annotated against source code files."`
+	Schema   subcommand.Schema   `cmd:"" help:"Export the JSON Schemas of the threat model files."`
+	Validate subcommand.Validate `cmd:"" help:"Validate the threat model files against their schema and the library."`
 }
 
 func main() {
@@ -0,0 +1,71 @@
+package report
+
+import (
+	"strconv"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// Assurance measures how many mitigations are verified by a test of their control for their component.
+type Assurance struct {
+	Tested   int `json:"tested"`
+	Untested int `json:"untested"`
+	// Percent is the share of tested mitigations, 100 when there is no mitigation.
+	Percent int `json:"percent"`
+	// UntestedMitigations are the mitigations no test verifies, in the order of the threat model.
+	UntestedMitigations []library.Mitigate `json:"untested_mitigations"`
+}
+
+// newAssurance matches each mitigation with the tests of the same control and component.
+func newAssurance(tm library.Threatmodel) Assurance {
+	a := Assurance{Percent: 100}
+	for _, m := range tm.Mitigations {
+		if len(testsFor(m, tm.Tests)) > 0 {
+			a.Tested++
+			continue
+		}
+
+		a.Untested++
+		a.UntestedMitigations = append(a.UntestedMitigations, m)
+	}
+
+	if total := a.Tested + a.Untested; total > 0 {
+		a.Percent = a.Tested * 100 / total
+	}
+
+	return a
+}
+
+// Coverage returns the share of tested mitigations as a percentage, or n/a when there is no mitigation to test.
+func (a Assurance) Coverage() string {
+	if a.Tested+a.Untested == 0 {
+		return "n/a"
+	}
+
+	return strconv.Itoa(a.Percent) + "%"
+}
+
+// untestedTable returns the mitigations no test verifies as a Markdown table.
+func (v *View) untestedTable(mitigations []library.Mitigate) string {
+	t := newRecordTable(len(mitigations), "Threat", "Component", "Control", "Source")
+	for row, m := range mitigations {
+		t.SetContent(row, 0, m.Threat).
+			SetContent(row, 1, m.Component).
+			SetContent(row, 2, m.Control).
+			SetRawContent(row, 3, v.sourceLink(m.Source))
+	}
+
+	return t.String()
+}
+
+// testsTable returns the tests as a Markdown table.
+func (v *View) testsTable(tests []library.Test) string {
+	t := newRecordTable(len(tests), "Control", "Component", "Source")
+	for row, test := range tests {
+		t.SetContent(row, 0, test.Control).
+			SetContent(row, 1, test.Component).
+			SetRawContent(row, 2, v.sourceLink(test.Source))
+	}
+
+	return t.String()
+}
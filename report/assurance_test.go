@@ -0,0 +1,52 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestNewAssurance(t *testing.T) {
+	escaping := library.Mitigate{Threat: "XSS", Component: "WebApp:Web", Control: "escaping"}
+	csp := library.Mitigate{Threat: "XSS", Component: "WebApp:Web", Control: "CSP"}
+	tests := []library.Test{{Control: "escaping", Component: "WebApp:Web"}}
+
+	cases := []struct {
+		name         string
+		mitigations  []library.Mitigate
+		wantTested   int
+		wantUntested int
+		wantCoverage string
+	}{
+		{name: "no mitigation", wantCoverage: "n/a"},
+		{name: "tested", mitigations: []library.Mitigate{escaping}, wantTested: 1, wantCoverage: "100%"},
+		{name: "untested", mitigations: []library.Mitigate{csp}, wantUntested: 1, wantCoverage: "0%"},
+		{name: "partly tested", mitigations: []library.Mitigate{escaping, csp, csp}, wantTested: 1, wantUntested: 2, wantCoverage: "33%"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAssurance(library.Threatmodel{Mitigations: tt.mitigations, Tests: tests})
+			if a.Tested != tt.wantTested || a.Untested != tt.wantUntested || len(a.UntestedMitigations) != tt.wantUntested {
+				t.Errorf("newAssurance() = %+v, want %d tested and %d untested", a, tt.wantTested, tt.wantUntested)
+			}
+			if got := a.Coverage(); got != tt.wantCoverage {
+				t.Errorf("Coverage() = %q, want %q", got, tt.wantCoverage)
+			}
+		})
+	}
+}
+
+func TestReportAssurance(t *testing.T) {
+	got, err := report(newView(newLibrary(library.Threatmodel{
+		Exposures: []library.Exposure{{Threat: "XSS", Component: "WebApp:Web"}},
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(got, "|Tested mitigations|n/a|\n") || strings.Contains(got, "100%") {
+		t.Errorf("report() = %s\nwant n/a tested mitigations", got)
+	}
+}
@@ -0,0 +1,98 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+type (
+	// ExposedWeakness is a weakness a component is exposed to, with the attack patterns of the library exploiting it.
+	ExposedWeakness struct {
+		Component      string          `json:"component"`
+		Weakness       string          `json:"weakness"`
+		Id             string          `json:"id"`
+		AttackPatterns []AttackPattern `json:"attack_patterns"`
+	}
+	// AttackPattern is an attack pattern imported from CAPEC, with the mitigations it suggests as candidate controls.
+	AttackPattern struct {
+		Id                string   `json:"id"`
+		Name              string   `json:"name"`
+		Likelihood        string   `json:"likelihood,omitempty"`
+		Severity          string   `json:"severity,omitempty"`
+		CandidateControls []string `json:"candidate_controls"`
+	}
+)
+
+// exposedWeaknesses returns the threats the components are exposed to which are exploited by an attack pattern, in
+// the order of the risk postures.
+func exposedWeaknesses(l *library.Library, postures []ComponentPosture) []ExposedWeakness {
+	var weaknesses []ExposedWeakness
+	for _, cp := range postures {
+		for _, p := range cp.Threats {
+			if p.Status != StatusExposed {
+				continue
+			}
+			threat, ok := l.FindThreat(p.Threat)
+			if !ok {
+				continue
+			}
+
+			w := ExposedWeakness{Component: cp.Component, Weakness: threat.Name, Id: threat.Id}
+			for _, id := range l.AttackPatterns(threat) {
+				pattern := l.Threats[id]
+				w.AttackPatterns = append(w.AttackPatterns, AttackPattern{
+					Id:                pattern.Id,
+					Name:              pattern.Name,
+					Likelihood:        customString(pattern.Custom, "likelihood"),
+					Severity:          customString(pattern.Custom, "severity"),
+					CandidateControls: library.CustomStrings(pattern.Custom, "candidate_controls"),
+				})
+			}
+			if len(w.AttackPatterns) > 0 {
+				weaknesses = append(weaknesses, w)
+			}
+		}
+	}
+
+	return weaknesses
+}
+
+// customString returns a custom string value, or an empty string if it is not set.
+func customString(custom library.Custom, key string) string {
+	if values := library.CustomStrings(custom, key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// attackPatternsTable returns the attack patterns exploiting the exposed weaknesses as a Markdown table.
+func attackPatternsTable(weaknesses []ExposedWeakness) string {
+	rows := 0
+	for _, w := range weaknesses {
+		rows += len(w.AttackPatterns)
+	}
+
+	t := newRecordTable(rows, "Component", "Weakness", "Attack pattern", "Likelihood", "Severity", "Candidate controls")
+	row := 0
+	for _, w := range weaknesses {
+		for _, p := range w.AttackPatterns {
+			controls := make([]string, 0, len(p.CandidateControls))
+			for _, c := range p.CandidateControls {
+				controls = append(controls, cellEscaper.Replace(c))
+			}
+
+			t.SetContent(row, 0, w.Component).
+				SetContent(row, 1, fmt.Sprintf("%s (%s)", w.Weakness, w.Id)).
+				SetContent(row, 2, fmt.Sprintf("%s (%s)", p.Name, p.Id)).
+				SetContent(row, 3, p.Likelihood).
+				SetContent(row, 4, p.Severity).
+				SetRawContent(row, 5, strings.Join(controls, "<br>"))
+			row++
+		}
+	}
+
+	return t.String()
+}
@@ -0,0 +1,143 @@
+package report
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+type (
+	// node is a component of the data-flow diagram with the threats annotated on it.
+	node struct {
+		id          string
+		name        string
+		cluster     *cluster
+		exposures   []string
+		mitigations []string
+	}
+	// cluster is a trust boundary of the data-flow diagram, drawn for every prefix of the component paths.
+	cluster struct {
+		id       string
+		name     string
+		path     string
+		clusters []*cluster
+		nodes    []*node
+	}
+	// edge is a connection or a transfer between two components of the data-flow diagram.
+	edge struct {
+		from          *node
+		to            *node
+		label         string
+		bidirectional bool
+		transfer      bool
+		crossing      bool
+	}
+	// diagram is the data-flow graph shared by the diagram renderers.
+	diagram struct {
+		root  *cluster
+		nodes []*node
+		edges []edge
+	}
+)
+
+// newDiagram builds the data-flow graph of the threat model. Nodes are the components referenced by
+// connections, transfers, exposures and mitigations, sorted by name so that ids are stable.
+func newDiagram(l *library.Library) *diagram {
+	names := map[string]bool{}
+	for _, c := range l.ThreatModel.Connections {
+		names[c.SourceComponent] = true
+		names[c.DestinationComponent] = true
+	}
+	for _, t := range l.ThreatModel.Transfers {
+		names[t.SourceComponent] = true
+		names[t.DestinationComponent] = true
+	}
+	for _, e := range l.ThreatModel.Exposures {
+		names[e.Component] = true
+	}
+	for _, m := range l.ThreatModel.Mitigations {
+		names[m.Component] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	d := &diagram{root: &cluster{}}
+	clusters := map[string]*cluster{"": d.root}
+	nodes := map[string]*node{}
+	for i, name := range sorted {
+		n := &node{id: "n" + strconv.Itoa(i), name: name, cluster: d.root}
+		nodes[name] = n
+		d.nodes = append(d.nodes, n)
+
+		for _, element := range componentPath(l, name) {
+			path := element
+			if n.cluster.path != "" {
+				path = n.cluster.path + ":" + element
+			}
+
+			c, ok := clusters[path]
+			if !ok {
+				c = &cluster{id: "c" + strconv.Itoa(len(clusters)-1), name: element, path: path}
+				clusters[path] = c
+				n.cluster.clusters = append(n.cluster.clusters, c)
+			}
+			n.cluster = c
+		}
+		n.cluster.nodes = append(n.cluster.nodes, n)
+	}
+
+	for _, e := range l.ThreatModel.Exposures {
+		nodes[e.Component].exposures = append(nodes[e.Component].exposures, e.Threat)
+	}
+	for _, m := range l.ThreatModel.Mitigations {
+		nodes[m.Component].mitigations = append(nodes[m.Component].mitigations, m.Threat)
+	}
+
+	for _, c := range l.ThreatModel.Connections {
+		d.edges = append(d.edges, edge{
+			from:          nodes[c.SourceComponent],
+			to:            nodes[c.DestinationComponent],
+			label:         c.Details,
+			bidirectional: c.Direction == "with",
+			crossing:      nodes[c.SourceComponent].cluster != nodes[c.DestinationComponent].cluster,
+		})
+	}
+	for _, t := range l.ThreatModel.Transfers {
+		d.edges = append(d.edges, edge{
+			from:     nodes[t.SourceComponent],
+			to:       nodes[t.DestinationComponent],
+			label:    t.Threat,
+			transfer: true,
+			crossing: nodes[t.SourceComponent].cluster != nodes[t.DestinationComponent].cluster,
+		})
+	}
+
+	return d
+}
+
+// componentPath returns the trust boundaries enclosing a component, outermost first,
+// taken from the path recorded in the library or else from the prefixes of its name.
+func componentPath(l *library.Library, name string) []string {
+	if c, ok := l.FindComponent(name); ok && len(c.Paths) > 0 {
+		return c.Paths[0]
+	}
+
+	elements := strings.Split(name, ":")
+
+	return elements[:len(elements)-1]
+}
+
+// boundary returns the name of the trust boundary of a cluster, for display.
+func (c *cluster) boundary() string {
+	if c.path == "" {
+		return "outside any boundary"
+	}
+
+	return c.path
+}
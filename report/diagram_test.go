@@ -0,0 +1,87 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestNewDiagram(t *testing.T) {
+	tests := []struct {
+		name      string
+		tm        library.Threatmodel
+		paths     map[string][]string
+		clusters  map[string]string
+		crossings []bool
+	}{
+		{
+			name: "nested boundaries",
+			tm: library.Threatmodel{Connections: []library.Connection{
+				{SourceComponent: "Cloud:VPC:Web", DestinationComponent: "Cloud:VPC:DB", Direction: "to"},
+				{SourceComponent: "User:Browser", DestinationComponent: "Cloud:VPC:Web", Direction: "to"},
+			}},
+			clusters:  map[string]string{"Cloud:VPC:DB": "Cloud:VPC", "Cloud:VPC:Web": "Cloud:VPC", "User:Browser": "User"},
+			crossings: []bool{false, true},
+		},
+		{
+			name: "outside any boundary",
+			tm: library.Threatmodel{Transfers: []library.Transfer{
+				{Threat: "DoS", SourceComponent: "Web", DestinationComponent: "CDN"},
+			}},
+			clusters:  map[string]string{"CDN": "", "Web": ""},
+			crossings: []bool{false},
+		},
+		{
+			name: "path of the library",
+			tm: library.Threatmodel{Connections: []library.Connection{
+				{SourceComponent: "Web", DestinationComponent: "Internet:Client", Direction: "to"},
+			}},
+			paths:     map[string][]string{"Web": {"DMZ"}},
+			clusters:  map[string]string{"Internet:Client": "Internet", "Web": "DMZ"},
+			crossings: []bool{true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLibrary(tt.tm)
+			for name, path := range tt.paths {
+				c := l.Components[name]
+				c.Paths = [][]string{path}
+				l.Components[name] = c
+			}
+
+			d := newDiagram(l)
+			clusters := map[string]string{}
+			for _, n := range d.nodes {
+				clusters[n.name] = n.cluster.path
+				if !containsNode(n.cluster.nodes, n) {
+					t.Errorf("newDiagram() did not add %s to its cluster", n.name)
+				}
+			}
+			if !reflect.DeepEqual(clusters, tt.clusters) {
+				t.Errorf("newDiagram() clusters = %v, want %v", clusters, tt.clusters)
+			}
+
+			crossings := make([]bool, len(d.edges))
+			for i, e := range d.edges {
+				crossings[i] = e.crossing
+			}
+			if !reflect.DeepEqual(crossings, tt.crossings) {
+				t.Errorf("newDiagram() crossings = %v, want %v", crossings, tt.crossings)
+			}
+		})
+	}
+}
+
+// containsNode reports whether nodes holds n.
+func containsNode(nodes []*node, n *node) bool {
+	for _, m := range nodes {
+		if m == n {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/phuslu/log"
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+const (
+	dotFile = "threatmodel.dot"
+	svgFile = "threatmodel.svg"
+	pngFile = "threatmodel.png"
+)
+
+// dotEscaper escapes the characters that would end or corrupt a quoted Graphviz string.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Dot returns the data-flow diagram of the threat model in the Graphviz DOT language.
+// Exposed components are filled in red, mitigated ones in green, components are grouped in their trust
+// boundaries and the flows crossing a boundary are drawn in red.
+func Dot(l *library.Library) string {
+	d := newDiagram(l)
+
+	var b strings.Builder
+	b.WriteString("digraph threatmodel {\n")
+	b.WriteString("    rankdir=LR;\n")
+	b.WriteString("    node [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\", fontname=\"Helvetica\"];\n")
+	b.WriteString("    edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	writeDotCluster(&b, d.root, 1)
+
+	for _, e := range d.edges {
+		attributes := fmt.Sprintf("label=\"%s\"", dotEscaper.Replace(e.label))
+		switch {
+		case e.transfer:
+			attributes += ", style=dashed"
+		case e.bidirectional:
+			attributes += ", dir=both"
+		}
+		if e.crossing {
+			attributes += `, color="#cc0000", fontcolor="#cc0000", penwidth=2`
+		}
+
+		fmt.Fprintf(&b, "    %s -> %s [%s];\n", e.from.id, e.to.id, attributes)
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// writeDotCluster writes the nodes of a cluster followed by its nested clusters as subgraphs.
+func writeDotCluster(b *strings.Builder, c *cluster, depth int) {
+	indent := strings.Repeat("    ", depth)
+
+	for _, n := range c.nodes {
+		lines := []string{dotEscaper.Replace(n.name)}
+		for _, threat := range n.exposures {
+			lines = append(lines, "exposed to "+dotEscaper.Replace(threat))
+		}
+		for _, threat := range n.mitigations {
+			lines = append(lines, "mitigates "+dotEscaper.Replace(threat))
+		}
+
+		attributes := ""
+		switch {
+		case len(n.exposures) > 0:
+			attributes = `, fillcolor="#ffdddd", color="#cc0000"`
+		case len(n.mitigations) > 0:
+			attributes = `, fillcolor="#ddffdd", color="#008800"`
+		}
+
+		fmt.Fprintf(b, "%s%s [label=\"%s\"%s];\n", indent, n.id, strings.Join(lines, `\n`), attributes)
+	}
+
+	for _, child := range c.clusters {
+		fmt.Fprintf(b, "%ssubgraph cluster_%s {\n", indent, child.id)
+		fmt.Fprintf(b, "%s    label=\"%s\";\n", indent, dotEscaper.Replace(child.name))
+		fmt.Fprintf(b, "%s    style=dashed;\n", indent)
+		fmt.Fprintf(b, "%s    color=\"#888888\";\n", indent)
+		writeDotCluster(b, child, depth+1)
+		fmt.Fprintf(b, "%s}\n", indent)
+	}
+}
+
+// Graphviz writes the DOT diagram of the threat model to the threat model directory and, when the dot binary is
+// available on the PATH, renders it to SVG and PNG. It returns the path of the SVG image, or an empty string if
+// nothing was rendered.
+func Graphviz(l *library.Library, dir string) (string, error) {
+	dotPath, svgPath, pngPath := filepath.Join(dir, dotFile), filepath.Join(dir, svgFile), filepath.Join(dir, pngFile)
+
+	if err := os.WriteFile(dotPath, []byte(Dot(l)), 0o600); err != nil {
+		return "", eris.Wrapf(err, "failed to write %s", dotPath)
+	}
+
+	bin, err := exec.LookPath("dot")
+	if err != nil {
+		log.Warn().Msg("graphviz dot binary not found in PATH, the diagram will only be available as " + dotPath)
+		return "", nil
+	}
+
+	for _, output := range []string{svgPath, pngPath} {
+		if out, err := exec.Command(bin, "-T"+strings.TrimPrefix(filepath.Ext(output), "."), "-o", output, dotPath).CombinedOutput(); err != nil {
+			log.Warn().Err(err).Str("output", string(out)).Msg("failed to render " + output + " with graphviz")
+			return "", nil
+		}
+	}
+
+	return svgPath, nil
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestDot(t *testing.T) {
+	tests := []struct {
+		name string
+		tm   library.Threatmodel
+		want []string
+	}{
+		{
+			name: "connection",
+			tm: library.Threatmodel{Connections: []library.Connection{
+				{SourceComponent: "User:Browser", DestinationComponent: "WebApp:Web", Direction: "to", Details: "HTTPS"},
+			}},
+			want: []string{
+				`    subgraph cluster_c0 {`,
+				`        label="User";`,
+				`        n0 [label="User:Browser"];`,
+				`    n0 -> n1 [label="HTTPS", color="#cc0000", fontcolor="#cc0000", penwidth=2];`,
+			},
+		},
+		{
+			name: "quotes, brackets and backslashes",
+			tm: library.Threatmodel{
+				Connections: []library.Connection{
+					{SourceComponent: `Web "front" [v2]`, DestinationComponent: `C:\DB`, Direction: "with", Details: "say \"hi\"\nthen {bye}"},
+				},
+				Mitigations: []library.Mitigate{{Threat: `XSS "stored"`, Component: `C:\DB`, Control: "escaping"}},
+			},
+			want: []string{
+				`        label="C";`,
+				`        n0 [label="C:\\DB\nmitigates XSS \"stored\"", fillcolor="#ddffdd", color="#008800"];`,
+				`    n1 [label="Web \"front\" [v2]"];`,
+				`    n1 -> n0 [label="say \"hi\"\nthen {bye}", dir=both, color="#cc0000", fontcolor="#cc0000", penwidth=2];`,
+			},
+		},
+		{
+			name: "transfer",
+			tm: library.Threatmodel{Transfers: []library.Transfer{
+				{Threat: "DoS", SourceComponent: "WebApp:Web", DestinationComponent: "WebApp:CDN"},
+			}},
+			want: []string{
+				`    n1 -> n0 [label="DoS", style=dashed];`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Dot(newLibrary(tt.tm))
+			if !strings.HasPrefix(got, "digraph threatmodel {\n") || !strings.HasSuffix(got, "}\n") {
+				t.Errorf("Dot() = %q, want a digraph", got)
+			}
+			for _, line := range tt.want {
+				if !strings.Contains(got, line+"\n") {
+					t.Errorf("Dot() = %s\nwant line %q", got, line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/config"
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// record is a row of an exported register: its values, in the order of the columns, and its custom data.
+type record struct {
+	values []string
+	custom library.Custom
+}
+
+// register is the exported file of a type of record.
+type register struct {
+	filename string
+	columns  []string
+	records  []record
+}
+
+// sourceColumns are the columns locating the annotation of a threat model record.
+var sourceColumns = []string{"filename", "line", "annotation"}
+
+// source returns the values of the source columns of an annotation.
+func source(s library.Source) []string {
+	return []string{s.Filename, strconv.Itoa(s.Line), s.Annotation}
+}
+
+// FileExport writes a register of every type of record of the library and of the threat model to the directory, in
+// the csv format.
+func FileExport(format, dir string) error {
+	if format != "csv" {
+		return eris.Errorf("unsupported export format %s", format)
+	}
+
+	cfg, err := config.LoadFile()
+	if err != nil {
+		return err
+	}
+
+	l, err := readLibrary(cfg.ThreatModelDir)
+	if err != nil {
+		return err
+	}
+
+	pages := map[string]string{}
+	for _, r := range registers(l) {
+		content, err := r.CSV()
+		if err != nil {
+			return eris.Wrapf(err, "failed to export %s", r.filename)
+		}
+		pages[r.filename] = content
+	}
+
+	return writePages(dir, pages)
+}
+
+// registers returns the registers of the threat model records, then of the library entries with the assessed threats.
+func registers(l *library.Library) []register {
+	tm := l.ThreatModel
+	registers := []register{
+		{filename: "mitigations.csv", columns: []string{"threat", "component", "control", "description"}},
+		{filename: "exposures.csv", columns: []string{"threat", "component", "details", "description"}},
+		{filename: "acceptances.csv", columns: []string{"threat", "component", "details", "description"}},
+		{filename: "transfers.csv", columns: []string{"threat", "source_component", "destination_component", "details", "description"}},
+		{filename: "connections.csv", columns: []string{"source_component", "destination_component", "direction", "details", "description"}},
+		{filename: "reviews.csv", columns: []string{"component", "details", "description"}},
+		{filename: "tests.csv", columns: []string{"control", "component", "description"}},
+	}
+	for i := range registers {
+		registers[i].columns = append(registers[i].columns, sourceColumns...)
+	}
+
+	for _, m := range tm.Mitigations {
+		registers[0].add(m.Custom, append([]string{m.Threat, m.Component, m.Control, m.Description}, source(m.Source)...)...)
+	}
+	for _, e := range tm.Exposures {
+		registers[1].add(e.Custom, append([]string{e.Threat, e.Component, e.Details, e.Description}, source(e.Source)...)...)
+	}
+	for _, a := range tm.Acceptances {
+		registers[2].add(a.Custom, append([]string{a.Threat, a.Component, a.Details, a.Description}, source(a.Source)...)...)
+	}
+	for _, t := range tm.Transfers {
+		registers[3].add(t.Custom, append([]string{t.Threat, t.SourceComponent, t.DestinationComponent, t.Details, t.Description}, source(t.Source)...)...)
+	}
+	for _, c := range tm.Connections {
+		registers[4].add(c.Custom, append([]string{c.SourceComponent, c.DestinationComponent, c.Direction, c.Details, c.Description}, source(c.Source)...)...)
+	}
+	for _, r := range tm.Reviews {
+		registers[5].add(r.Custom, append([]string{r.Component, r.Details, r.Description}, source(r.Source)...)...)
+	}
+	for _, t := range tm.Tests {
+		registers[6].add(t.Custom, append([]string{t.Control, t.Component, t.Description}, source(t.Source)...)...)
+	}
+
+	threats := register{filename: "threats.csv", columns: []string{"id", "name", "description"}}
+	assessed := l.AssessedThreats()
+	for _, id := range sortedNames(assessed) {
+		t := assessed[id]
+		threats.add(t.Custom, t.Id, t.Name, t.Description)
+	}
+	controls := register{filename: "controls.csv", columns: []string{"id", "name", "description"}}
+	for _, id := range sortedNames(l.Controls) {
+		c := l.Controls[id]
+		controls.add(c.Custom, c.Id, c.Name, c.Description)
+	}
+	components := register{filename: "components.csv", columns: []string{"id", "name", "description", "paths"}}
+	for _, id := range sortedNames(l.Components) {
+		c := l.Components[id]
+		var paths []string
+		for _, path := range c.Paths {
+			paths = append(paths, strings.Join(path, ":"))
+		}
+		components.add(c.Custom, c.Id, c.Name, c.Description, strings.Join(paths, "; "))
+	}
+
+	return append(registers, threats, controls, components)
+}
+
+// add adds a record to the register.
+func (r *register) add(custom library.Custom, values ...string) {
+	r.records = append(r.records, record{values: values, custom: custom})
+}
+
+// CSV returns the register as RFC 4180 CSV. The custom data of the records is flattened into a custom.<key> column
+// per key, nested keys joined by dots, sorted after the other columns. Cells are neutralized like csvCell.
+func (r *register) CSV() (string, error) {
+	flattened := make([]map[string]string, len(r.records))
+	keys := map[string]bool{}
+	for i, rec := range r.records {
+		flattened[i] = map[string]string{}
+		flatten("custom", map[string]interface{}(rec.custom), flattened[i])
+		for key := range flattened[i] {
+			keys[key] = true
+		}
+	}
+	custom := make([]string, 0, len(keys))
+	for key := range keys {
+		custom = append(custom, key)
+	}
+	sort.Strings(custom)
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.UseCRLF = true
+
+	if err := w.Write(append(append([]string{}, r.columns...), custom...)); err != nil {
+		return "", eris.Wrap(err, "failed to write csv header")
+	}
+	for i, rec := range r.records {
+		row := csvCells(rec.values)
+		for _, key := range custom {
+			row = append(row, csvCell(flattened[i][key]))
+		}
+		if err := w.Write(row); err != nil {
+			return "", eris.Wrap(err, "failed to write csv row")
+		}
+	}
+	w.Flush()
+
+	return b.String(), eris.Wrap(w.Error(), "failed to write csv")
+}
+
+// csvCell neutralizes a cell which spreadsheets would evaluate as a formula, starting with =, +, -, @, a tab or a
+// carriage return, by prefixing it with a quote.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// csvCells returns a copy of the cells neutralized by csvCell.
+func csvCells(values []string) []string {
+	cells := make([]string, len(values))
+	for i, value := range values {
+		cells[i] = csvCell(value)
+	}
+
+	return cells
+}
+
+// flatten adds the values of the custom data to the columns, named after their key prefixed with the keys of the
+// objects they are nested in. Other values than strings are written as JSON.
+func flatten(prefix string, value interface{}, columns map[string]string) {
+	switch v := value.(type) {
+	case nil:
+	case map[string]interface{}:
+		for key, nested := range v {
+			flatten(prefix+"."+key, nested, columns)
+		}
+	case string:
+		columns[prefix] = v
+	default:
+		b, _ := json.Marshal(v)
+		columns[prefix] = string(b)
+	}
+}
@@ -0,0 +1,48 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestRegisterCSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		custom library.Custom
+		want   string
+	}{
+		{
+			name:   "plain",
+			values: []string{"SQL injection", "WebApp:Web"},
+			want:   "threat,component\r\nSQL injection,WebApp:Web\r\n",
+		},
+		{
+			name:   "formulas",
+			values: []string{`=HYPERLINK("x")`, "+1", "-1", "@SUM(A1)", "\tx", "\rx"},
+			want:   "threat,component\r\n\"'=HYPERLINK(\"\"x\"\")\",'+1,'-1,'@SUM(A1),'\tx,\"'x\"\r\n",
+		},
+		{
+			name:   "custom data",
+			values: []string{"SQL injection", "WebApp:Web"},
+			custom: library.Custom{"owner": "=cmd", "risk": map[string]interface{}{"score": 7.5}},
+			want:   "threat,component,custom.owner,custom.risk.score\r\nSQL injection,WebApp:Web,'=cmd,7.5\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := register{filename: "test.csv", columns: []string{"threat", "component"}}
+			r.add(tt.custom, tt.values...)
+
+			got, err := r.CSV()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("CSV() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package report
+
+import (
+	_ "embed"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+//go:embed templates/report.html.tmpl
+var htmlTemplate string
+
+type (
+	// htmlEntry is an annotation of the threat model as displayed, searched and filtered in the HTML report.
+	htmlEntry struct {
+		Verb       string
+		Title      string
+		Details    string
+		Components []string
+		Threat     string
+		Control    string
+		Source     library.Source
+		URL        string
+	}
+	// htmlView is the data the HTML report template is executed against.
+	htmlView struct {
+		Title      string
+		Generated  string
+		Diagram    template.HTML
+		Drawn      bool
+		Mermaid    string
+		Verbs      []string
+		Components []string
+		Threats    []string
+		Entries    []htmlEntry
+	}
+)
+
+// htmlReport returns the threat model as a single self-contained HTML document, with its styles and scripts embedded
+// so that it can be read offline. The Graphviz diagram is inlined when it could be rendered, or else a simpler
+// diagram drawn without Graphviz.
+func htmlReport(v *View) (string, error) {
+	l := v.Library
+	view := htmlView{
+		Title:     v.Project.Name + " threat model report",
+		Generated: v.Generated.Format(time.RFC822),
+		Mermaid:   v.Mermaid,
+		Entries:   htmlEntries(v),
+	}
+
+	if v.imagePath != "" {
+		svg, err := os.ReadFile(v.imagePath)
+		if err != nil {
+			return "", eris.Wrapf(err, "failed to read %s", v.imagePath)
+		}
+
+		// Drop the XML prolog and doctype so that the image can be inlined.
+		if i := strings.Index(string(svg), "<svg"); i >= 0 {
+			view.Diagram = template.HTML(svg[i:]) //nolint:gosec // generated by graphviz from escaped labels
+		}
+	}
+	if view.Diagram == "" && len(l.Components) > 0 {
+		view.Diagram = template.HTML(svgDiagram(newDiagram(l))) //nolint:gosec // labels are escaped
+		view.Drawn = true
+	}
+
+	verbs, components, threats := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, entry := range view.Entries {
+		verbs[entry.Verb] = true
+		for _, component := range entry.Components {
+			components[component] = true
+		}
+		if entry.Threat != "" {
+			threats[entry.Threat] = true
+		}
+	}
+	for _, c := range l.Components {
+		components[c.Name] = true
+	}
+	view.Verbs, view.Components, view.Threats = keys(verbs), keys(components), keys(threats)
+
+	t, err := template.New("report").Funcs(template.FuncMap{"join": strings.Join}).Parse(htmlTemplate)
+	if err != nil {
+		return "", eris.Wrap(err, "failed to parse html template")
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, view); err != nil {
+		return "", eris.Wrap(err, "failed to execute html template")
+	}
+
+	return b.String(), nil
+}
+
+// htmlEntries flattens every annotation of the threat model into entries of the HTML report.
+func htmlEntries(v *View) []htmlEntry {
+	l := v.Library
+
+	var entries []htmlEntry
+
+	for _, m := range l.ThreatModel.Mitigations {
+		entries = append(entries, htmlEntry{
+			Verb: "mitigates", Title: m.Threat + " against " + m.Component + " mitigated by " + m.Control,
+			Components: []string{m.Component}, Threat: m.Threat, Control: m.Control, Source: m.Source,
+		})
+	}
+	for _, e := range l.ThreatModel.Exposures {
+		entries = append(entries, htmlEntry{
+			Verb: "exposes", Title: e.Component + " exposed to " + e.Threat, Details: e.Details,
+			Components: []string{e.Component}, Threat: e.Threat, Source: e.Source,
+		})
+	}
+	for _, a := range l.ThreatModel.Acceptances {
+		entries = append(entries, htmlEntry{
+			Verb: "accepts", Title: a.Threat + " accepted to " + a.Component, Details: a.Details,
+			Components: []string{a.Component}, Threat: a.Threat, Source: a.Source,
+		})
+	}
+	for _, t := range l.ThreatModel.Transfers {
+		entries = append(entries, htmlEntry{
+			Verb: "transfers", Title: t.Threat + " transferred from " + t.SourceComponent + " to " + t.DestinationComponent, Details: t.Details,
+			Components: []string{t.SourceComponent, t.DestinationComponent}, Threat: t.Threat, Source: t.Source,
+		})
+	}
+	for _, c := range l.ThreatModel.Connections {
+		entries = append(entries, htmlEntry{
+			Verb: "connects", Title: c.SourceComponent + " " + c.Direction + " " + c.DestinationComponent, Details: c.Details,
+			Components: []string{c.SourceComponent, c.DestinationComponent}, Source: c.Source,
+		})
+	}
+	for _, r := range l.ThreatModel.Reviews {
+		entries = append(entries, htmlEntry{
+			Verb: "reviews", Title: r.Component, Details: r.Details,
+			Components: []string{r.Component}, Source: r.Source,
+		})
+	}
+	for _, t := range l.ThreatModel.Tests {
+		entries = append(entries, htmlEntry{
+			Verb: "tests", Title: t.Control + " tested for " + t.Component,
+			Components: []string{t.Component}, Control: t.Control, Source: t.Source,
+		})
+	}
+
+	for i := range entries {
+		entries[i].URL = v.SourceURL(entries[i].Source)
+	}
+
+	return entries
+}
+
+// keys returns the keys of a set, sorted.
+func keys(set map[string]bool) []string {
+	sorted := make([]string, 0, len(set))
+	for key := range set {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// newView returns the view of a library, as NewView would without a configuration file.
+func newView(l *library.Library) *View {
+	return &View{
+		Project:     Project{Name: "shop"},
+		Generated:   time.Unix(0, 0).UTC(),
+		Library:     l,
+		Threats:     l.AssessedThreats(),
+		ThreatModel: l.ThreatModel,
+		Mermaid:     Mermaid(l),
+		Postures:    postures(l),
+		Matrix:      NewMatrix(l),
+		Assurance:   newAssurance(l.ThreatModel),
+	}
+}
+
+func TestHTMLReport(t *testing.T) {
+	svg := filepath.Join(t.TempDir(), svgFile)
+	if err := os.WriteFile(svg, []byte(`<?xml version="1.0"?><!DOCTYPE svg><svg id="graphviz"></svg>`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := library.Threatmodel{
+		Exposures: []library.Exposure{{Threat: "XSS", Component: "WebApp:Web", Details: "<script>alert(1)</script>"}},
+	}
+
+	tests := []struct {
+		name    string
+		image   string
+		imports map[string]string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "drawn without graphviz",
+			want: []string{
+				`<svg xmlns="http://www.w3.org/2000/svg"`,
+				`Graphviz was not found`,
+				`&lt;script&gt;alert(1)&lt;/script&gt;`,
+				`data-component="WebApp:Web"`,
+			},
+			notWant: []string{`<script>alert(1)`},
+		},
+		{
+			name:    "rendered by graphviz",
+			image:   svg,
+			want:    []string{`<svg id="graphviz"></svg>`},
+			notWant: []string{`Graphviz was not found`, `<?xml`},
+		},
+		{
+			name:    "imported component",
+			imports: map[string]string{"#db": "WebApp:DB"},
+			want:    []string{`data-component="WebApp:DB"`},
+			notWant: []string{`data-component="#db"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLibrary(tm)
+			for id, name := range tt.imports {
+				l.Components[id] = library.Component{Id: id, Name: name}
+			}
+			v := newView(l)
+			v.imagePath = tt.image
+
+			got, err := htmlReport(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("htmlReport() does not contain %q", s)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("htmlReport() contains %q", s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package report
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// jsonView is the machine-readable report.
+type jsonView struct {
+	Project     Project                      `json:"project"`
+	Generated   time.Time                    `json:"generated"`
+	Commit      string                       `json:"commit"`
+	Statistics  Statistics                   `json:"statistics"`
+	Summary     Summary                      `json:"summary"`
+	Components  map[string]library.Component `json:"components"`
+	Controls    map[string]library.Control   `json:"controls"`
+	Threats     map[string]library.Threat    `json:"threats"`
+	ThreatModel library.Threatmodel          `json:"threat_model"`
+	Postures    []ComponentPosture           `json:"postures"`
+	Weaknesses  []ExposedWeakness            `json:"weaknesses"`
+	Assurance   Assurance                    `json:"assurance"`
+	Crossings   []Crossing                   `json:"crossings"`
+	Matrix      *Matrix                      `json:"matrix"`
+	Trend       Trend                        `json:"trend"`
+}
+
+// jsonReport returns the library, the threat model and the computed analyses as indented JSON.
+func jsonReport(v *View) (string, error) {
+	b, err := json.MarshalIndent(jsonView{
+		Project:     v.Project,
+		Generated:   v.Generated,
+		Commit:      v.Commit,
+		Statistics:  v.Statistics,
+		Summary:     v.Summary,
+		Components:  v.Library.Components,
+		Controls:    v.Library.Controls,
+		Threats:     v.Threats,
+		ThreatModel: v.ThreatModel,
+		Postures:    v.Postures,
+		Weaknesses:  v.Weaknesses,
+		Assurance:   v.Assurance,
+		Crossings:   v.Crossings,
+		Matrix:      v.Matrix,
+		Trend:       v.Trend,
+	}, "", " ")
+	if err != nil {
+		return "", eris.Wrap(err, "failed to marshal report")
+	}
+
+	return string(b) + "\n", nil
+}
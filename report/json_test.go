@@ -0,0 +1,43 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestJSONReport(t *testing.T) {
+	dir := newProject(t, newLibrary(library.Threatmodel{
+		Mitigations: []library.Mitigate{{Threat: "XSS", Component: "WebApp:Web", Control: "escaping"}},
+		Exposures:   []library.Exposure{{Threat: "SQLi", Component: "WebApp:DB", Details: "raw queries"}},
+	}))
+
+	if err := FileReport(Options{Formats: []string{"json"}, Generated: time.Unix(0, 0).UTC()}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got jsonView
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Statistics.Threats != len(got.Threats) || len(got.Threats) != 2 {
+		t.Errorf("jsonReport() counts %d threats, lists %d, want 2", got.Statistics.Threats, len(got.Threats))
+	}
+	if got.Statistics.Mitigations != 1 || got.Statistics.Exposures != 1 || len(got.ThreatModel.Exposures) != 1 {
+		t.Errorf("jsonReport() statistics = %+v, want 1 mitigation and 1 exposure", got.Statistics)
+	}
+	if len(got.Postures) != 2 || got.Postures[0].Component != "WebApp:DB" || got.Postures[0].Threats[0].Status != StatusExposed {
+		t.Errorf("jsonReport() postures = %+v, want the exposed WebApp:DB first", got.Postures)
+	}
+	if !got.Generated.Equal(time.Unix(0, 0)) {
+		t.Errorf("jsonReport() generated = %v, want the epoch", got.Generated)
+	}
+}
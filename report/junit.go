@@ -0,0 +1,135 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// placeholders are the details of an acceptance which do not justify it.
+var placeholders = map[string]bool{"": true, "todo": true, "tbd": true, "fixme": true, "n/a": true}
+
+// JUnit XML report, as understood by CI test results views.
+type (
+	junitTestsuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Skipped  int              `xml:"skipped,attr"`
+		Suites   []junitTestsuite `xml:"testsuite"`
+	}
+	junitTestsuite struct {
+		Name      string          `xml:"name,attr"`
+		Tests     int             `xml:"tests,attr"`
+		Failures  int             `xml:"failures,attr"`
+		Skipped   int             `xml:"skipped,attr"`
+		Timestamp string          `xml:"timestamp,attr"`
+		Cases     []junitTestcase `xml:"testcase"`
+	}
+	junitTestcase struct {
+		Name      string        `xml:"name,attr"`
+		Classname string        `xml:"classname,attr"`
+		File      string        `xml:"file,attr,omitempty"`
+		Line      int           `xml:"line,attr,omitempty"`
+		Failure   *junitFailure `xml:"failure"`
+		Skipped   *junitSkipped `xml:"skipped"`
+	}
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+	junitSkipped struct {
+		Message string `xml:"message,attr"`
+	}
+)
+
+// junitReport returns the checks of the threat model as JUnit XML test cases: every exposure is mitigated, every
+// mitigation has a test and every acceptance is justified. Failures are located at their annotation.
+func junitReport(v *View) (string, error) {
+	status := map[string]map[string]string{}
+	for _, cp := range v.Postures {
+		status[cp.Component] = map[string]string{}
+		for _, p := range cp.Threats {
+			status[cp.Component][p.Threat] = p.Status
+		}
+	}
+
+	exposures := junitTestsuite{Name: "exposures are mitigated"}
+	for _, e := range v.ThreatModel.Exposures {
+		c := junitCase(fmt.Sprintf("%s to %s with %s is mitigated", e.Component, e.Threat, e.Details), "threatmodel.exposures", e.Source)
+		switch s := status[e.Component][e.Threat]; s {
+		case StatusExposed:
+			c.Failure = junitFailed(s, e.Source)
+		case StatusAccepted:
+			c.Skipped = &junitSkipped{Message: s}
+		}
+		exposures.add(c)
+	}
+
+	mitigations := junitTestsuite{Name: "mitigations are tested"}
+	for _, m := range v.ThreatModel.Mitigations {
+		c := junitCase(fmt.Sprintf("%s against %s with %s has tests", m.Component, m.Threat, m.Control), "threatmodel.mitigations", m.Source)
+		if len(testsFor(m, v.ThreatModel.Tests)) == 0 {
+			c.Failure = junitFailed("no test of "+m.Control+" for "+m.Component, m.Source)
+		}
+		mitigations.add(c)
+	}
+
+	acceptances := junitTestsuite{Name: "acceptances are justified"}
+	for _, a := range v.ThreatModel.Acceptances {
+		c := junitCase(fmt.Sprintf("%s to %s has a justification", a.Threat, a.Component), "threatmodel.acceptances", a.Source)
+		if placeholders[strings.ToLower(strings.TrimSpace(a.Details))] {
+			c.Failure = junitFailed("no justification", a.Source)
+		}
+		acceptances.add(c)
+	}
+
+	suites := junitTestsuites{Name: v.Project.Name + " threat model"}
+	for _, s := range []junitTestsuite{exposures, mitigations, acceptances} {
+		s.Timestamp = v.Generated.UTC().Format(time.RFC3339)
+		suites.Tests += s.Tests
+		suites.Failures += s.Failures
+		suites.Skipped += s.Skipped
+		suites.Suites = append(suites.Suites, s)
+	}
+
+	b, err := xml.MarshalIndent(suites, "", " ")
+	if err != nil {
+		return "", eris.Wrap(err, "failed to marshal JUnit report")
+	}
+
+	return xml.Header + string(b) + "\n", nil
+}
+
+// add adds a test case to the suite and counts it.
+func (s *junitTestsuite) add(c junitTestcase) {
+	s.Tests++
+	if c.Failure != nil {
+		s.Failures++
+	}
+	if c.Skipped != nil {
+		s.Skipped++
+	}
+	s.Cases = append(s.Cases, c)
+}
+
+// junitCase returns the test case of a check of an annotation.
+func junitCase(name, classname string, source library.Source) junitTestcase {
+	return junitTestcase{Name: name, Classname: classname, File: source.Filename, Line: source.Line}
+}
+
+// junitFailed returns a failure located at the annotation.
+func junitFailed(message string, source library.Source) *junitFailure {
+	return &junitFailure{
+		Message: message,
+		Type:    "ThreatModelCheck",
+		Text:    fmt.Sprintf("%s:%d: %s", source.Filename, source.Line, source.Annotation),
+	}
+}
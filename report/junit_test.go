@@ -0,0 +1,79 @@
+package report
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestJUnitReport(t *testing.T) {
+	tests := []struct {
+		name         string
+		tm           library.Threatmodel
+		wantTests    []int
+		wantFailures []int
+		wantSkipped  []int
+	}{
+		{
+			name:         "empty",
+			wantTests:    []int{0, 0, 0},
+			wantFailures: []int{0, 0, 0},
+			wantSkipped:  []int{0, 0, 0},
+		},
+		{
+			name: "exposed, mitigated and accepted",
+			tm: library.Threatmodel{
+				Exposures: []library.Exposure{
+					{Threat: "XSS", Component: "WebApp:Web", Details: "unescaped names"},
+					{Threat: "SQL injection", Component: "WebApp:DB", Details: "raw queries"},
+					{Threat: "DoS", Component: "WebApp:API", Details: "no rate limit"},
+				},
+				Mitigations: []library.Mitigate{
+					{Threat: "SQL injection", Component: "WebApp:DB", Control: "prepared statements"},
+					{Threat: "CSRF", Component: "WebApp:Web", Control: "tokens"},
+				},
+				Acceptances: []library.Acceptance{
+					{Threat: "DoS", Component: "WebApp:API", Details: "rate limited upstream"},
+					{Threat: "Spoofing", Component: "WebApp:Web", Details: " TODO "},
+				},
+				Tests: []library.Test{{Control: "prepared statements", Component: "WebApp:DB"}},
+			},
+			wantTests:    []int{3, 2, 2},
+			wantFailures: []int{1, 1, 1},
+			wantSkipped:  []int{1, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := junitReport(newView(newLibrary(tt.tm)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got junitTestsuites
+			if err := xml.Unmarshal([]byte(out), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got.Suites) != len(tt.wantTests) {
+				t.Fatalf("junitReport() suites = %+v, want %d suites", got.Suites, len(tt.wantTests))
+			}
+			var tests, failures, skipped int
+			for i, s := range got.Suites {
+				if s.Tests != tt.wantTests[i] || s.Failures != tt.wantFailures[i] || s.Skipped != tt.wantSkipped[i] {
+					t.Errorf("junitReport() suite %q counts %d tests, %d failures, %d skipped, want %d, %d, %d",
+						s.Name, s.Tests, s.Failures, s.Skipped, tt.wantTests[i], tt.wantFailures[i], tt.wantSkipped[i])
+				}
+				if len(s.Cases) != s.Tests {
+					t.Errorf("junitReport() suite %q holds %d cases, counts %d", s.Name, len(s.Cases), s.Tests)
+				}
+				tests, failures, skipped = tests+s.Tests, failures+s.Failures, skipped+s.Skipped
+			}
+			if got.Tests != tests || got.Failures != failures || got.Skipped != skipped {
+				t.Errorf("junitReport() counts %d tests, %d failures, %d skipped, want the sums %d, %d, %d",
+					got.Tests, got.Failures, got.Skipped, tests, failures, skipped)
+			}
+		})
+	}
+}
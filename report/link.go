@@ -0,0 +1,128 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// sourceURLPatterns are the source URL patterns of the well-known repository web UIs.
+var sourceURLPatterns = map[string]string{
+	"github":    "{repository}/blob/{commit}/{file}#L{line}",
+	"gitlab":    "{repository}/-/blob/{commit}/{file}#L{line}",
+	"gitea":     "{repository}/src/commit/{commit}/{file}#L{line}",
+	"bitbucket": "{repository}/src/{commit}/{file}#lines-{line}",
+}
+
+// sourceURLPattern returns the source URL pattern of the repository. The configured pattern is either the name of a
+// well-known web UI or a pattern of its own, when it is empty the web UI is guessed from the repository URL.
+func sourceURLPattern(repositoryURL, configured string) string {
+	if pattern, ok := sourceURLPatterns[configured]; ok {
+		return pattern
+	}
+	if configured != "" {
+		return configured
+	}
+
+	switch {
+	case strings.Contains(repositoryURL, "gitlab"):
+		return sourceURLPatterns["gitlab"]
+	case strings.Contains(repositoryURL, "gitea"):
+		return sourceURLPatterns["gitea"]
+	case strings.Contains(repositoryURL, "bitbucket"):
+		return sourceURLPatterns["bitbucket"]
+	default:
+		return sourceURLPatterns["github"]
+	}
+}
+
+// headCommit returns the SHA of the commit checked out in the git repository of the current directory,
+// or HEAD if it cannot be read, so that links point to the latest commit of the repository instead.
+func headCommit() string {
+	gitDir := ".git"
+
+	// In worktrees and submodules .git is a file pointing to the actual git directory.
+	if data, err := os.ReadFile(gitDir); err == nil && strings.HasPrefix(string(data), "gitdir:") {
+		gitDir = strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+	}
+
+	// The branches of linked worktrees are shared with the main worktree, in the common directory.
+	commonDir := gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "HEAD"
+	}
+
+	ref := strings.TrimSpace(string(head))
+	if !strings.HasPrefix(ref, "ref:") {
+		return ref
+	}
+	ref = strings.TrimSpace(strings.TrimPrefix(ref, "ref:"))
+
+	for _, dir := range []string{gitDir, commonDir} {
+		if sha, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(sha))
+		}
+	}
+
+	// Refs may have been packed by git gc.
+	packed, err := os.ReadFile(filepath.Join(commonDir, "packed-refs"))
+	if err != nil {
+		return "HEAD"
+	}
+	for _, line := range strings.Split(string(packed), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[1] == ref {
+			return fields[0]
+		}
+	}
+
+	return "HEAD"
+}
+
+// SourceURL returns the URL of the file and line of an annotation in the web UI of the repository, pinned to the
+// current commit. Without a repository URL in the configuration, it returns the path of the file relative to the
+// directory of the document being written.
+func (v *View) SourceURL(source library.Source) string {
+	if source.Filename == "" {
+		return ""
+	}
+
+	file := filepath.ToSlash(filepath.Clean(source.Filename))
+	line := strconv.Itoa(source.Line)
+
+	if v.RepositoryURL == "" {
+		return relativePath(v.outputDir, source.Filename) + "#L" + line
+	}
+
+	return strings.NewReplacer(
+		"{repository}", strings.TrimSuffix(v.RepositoryURL, "/"),
+		"{commit}", v.Commit,
+		"{file}", file,
+		"{line}", line,
+	).Replace(v.sourceURLPattern)
+}
+
+// relativePath returns the path of a file of the current directory relative to a directory, with forward slashes.
+func relativePath(dir, file string) string {
+	if dir != "" {
+		absDir, errDir := filepath.Abs(dir)
+		absFile, errFile := filepath.Abs(file)
+		if errDir == nil && errFile == nil {
+			if rel, err := filepath.Rel(absDir, absFile); err == nil {
+				file = rel
+			}
+		}
+	}
+
+	return filepath.ToSlash(filepath.Clean(file))
+}
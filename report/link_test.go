@@ -0,0 +1,124 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+const (
+	mainSHA     = "1111111111111111111111111111111111111111"
+	featureSHA  = "2222222222222222222222222222222222222222"
+	detachedSHA = "3333333333333333333333333333333333333333"
+)
+
+func TestHeadCommit(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{name: "not a repository", want: "HEAD"},
+		{
+			name:  "branch",
+			files: map[string]string{".git/HEAD": "ref: refs/heads/main\n", ".git/refs/heads/main": mainSHA + "\n"},
+			want:  mainSHA,
+		},
+		{
+			name:  "detached",
+			files: map[string]string{".git/HEAD": detachedSHA + "\n"},
+			want:  detachedSHA,
+		},
+		{
+			name: "packed branch",
+			files: map[string]string{
+				".git/HEAD":        "ref: refs/heads/main\n",
+				".git/packed-refs": "# pack-refs with: peeled fully-peeled sorted\n" + featureSHA + " refs/heads/feature\n" + mainSHA + " refs/heads/main\n",
+			},
+			want: mainSHA,
+		},
+		{
+			name:  "unborn branch",
+			files: map[string]string{".git/HEAD": "ref: refs/heads/main\n"},
+			want:  "HEAD",
+		},
+		{
+			name: "linked worktree",
+			files: map[string]string{
+				".git":                                  "gitdir: main/.git/worktrees/feature\n",
+				"main/.git/worktrees/feature/HEAD":      "ref: refs/heads/feature\n",
+				"main/.git/worktrees/feature/commondir": "../..\n",
+				"main/.git/refs/heads/feature":          featureSHA + "\n",
+			},
+			want: featureSHA,
+		},
+		{
+			name: "linked worktree with packed branch",
+			files: map[string]string{
+				".git":                                  "gitdir: main/.git/worktrees/feature\n",
+				"main/.git/worktrees/feature/HEAD":      "ref: refs/heads/feature\n",
+				"main/.git/worktrees/feature/commondir": "../..\n",
+				"main/.git/packed-refs":                 featureSHA + " refs/heads/feature\n",
+			},
+			want: featureSHA,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			chdir(t, dir)
+
+			if got := headCommit(); got != tt.want {
+				t.Errorf("headCommit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceURL(t *testing.T) {
+	source := library.Source{Filename: "web/handler.go", Line: 42}
+
+	tests := []struct {
+		name          string
+		repositoryURL string
+		configured    string
+		outputDir     string
+		source        library.Source
+		want          string
+	}{
+		{name: "unknown source", repositoryURL: "https://github.com/org/shop", want: ""},
+		{name: "github", repositoryURL: "https://github.com/org/shop/", source: source, want: "https://github.com/org/shop/blob/abc/web/handler.go#L42"},
+		{name: "gitlab", repositoryURL: "https://gitlab.com/org/shop", source: source, want: "https://gitlab.com/org/shop/-/blob/abc/web/handler.go#L42"},
+		{name: "bitbucket", repositoryURL: "https://bitbucket.org/org/shop", source: source, want: "https://bitbucket.org/org/shop/src/abc/web/handler.go#lines-42"},
+		{name: "named web UI", repositoryURL: "https://git.example.com/org/shop", configured: "gitea", source: source, want: "https://git.example.com/org/shop/src/commit/abc/web/handler.go#L42"},
+		{name: "custom pattern", repositoryURL: "https://cgit.example.com/shop", configured: "{repository}/tree/{file}?id={commit}#n{line}", source: source, want: "https://cgit.example.com/shop/tree/web/handler.go?id=abc#n42"},
+		{name: "no repository", source: source, want: "web/handler.go#L42"},
+		{name: "no repository, report in a directory", outputDir: "docs/site", source: source, want: "../../web/handler.go#L42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &View{
+				RepositoryURL:    tt.repositoryURL,
+				Commit:           "abc",
+				sourceURLPattern: sourceURLPattern(tt.repositoryURL, tt.configured),
+				outputDir:        tt.outputDir,
+			}
+			if got := v.SourceURL(tt.source); got != tt.want {
+				t.Errorf("SourceURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -127,16 +127,52 @@ func (m *Markdown) String() string {
 	return m.builder.String()
 }
 
+// Alignment is the alignment of the content of a table column.
+type Alignment int
+
+const (
+	AlignDefault Alignment = iota
+	AlignLeft
+	AlignCenter
+	AlignRight
+)
+
+// cellEscaper escapes the characters that would end a table cell or row, or be taken for an HTML tag.
+var cellEscaper = strings.NewReplacer("|", `\|`, "<", "&lt;", "\r\n", "<br>", "\n", "<br>")
+
 type Table struct {
 	body [][]string
 }
 
 func (t *Table) SetTitle(col int, content string) *Table {
-	t.body[0][col] = content
+	t.body[0][col] = cellEscaper.Replace(content)
 	return t
 }
 
+// SetAlignment sets the alignment of the content of a column.
+func (t *Table) SetAlignment(col int, alignment Alignment) *Table {
+	switch alignment {
+	case AlignLeft:
+		t.body[1][col] = ":---"
+	case AlignCenter:
+		t.body[1][col] = ":---:"
+	case AlignRight:
+		t.body[1][col] = "---:"
+	default:
+		t.body[1][col] = "----"
+	}
+	return t
+}
+
+// SetContent sets the content of a cell, escaping the pipes and newlines that would break the table.
 func (t *Table) SetContent(row, col int, content string) *Table {
+	row = row + 2
+	t.body[row][col] = cellEscaper.Replace(content)
+	return t
+}
+
+// SetRawContent sets the content of a cell as is, for content already escaped such as links.
+func (t *Table) SetRawContent(row, col int, content string) *Table {
 	row = row + 2
 	t.body[row][col] = content
 	return t
@@ -0,0 +1,50 @@
+package report
+
+import "testing"
+
+func TestTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		table func() *Table
+		want  string
+	}{
+		{
+			name: "content",
+			table: func() *Table {
+				return NewTable(1, 2).SetTitle(0, "Name").SetTitle(1, "Details").
+					SetContent(0, 0, "Web").SetContent(0, 1, "HTTPS")
+			},
+			want: "|Name|Details|\n|----|----|\n|Web|HTTPS|\n",
+		},
+		{
+			name: "pipes, newlines and tags",
+			table: func() *Table {
+				return NewTable(1, 1).SetTitle(0, "a|b").SetContent(0, 0, "one\ntwo\r\nthree <b>|")
+			},
+			want: "|a\\|b|\n|----|\n|one<br>two<br>three &lt;b>\\||\n",
+		},
+		{
+			name: "raw content",
+			table: func() *Table {
+				return NewTable(1, 1).SetTitle(0, "Source").SetRawContent(0, 0, "[a.go:1](a.go#L1)<br>none")
+			},
+			want: "|Source|\n|----|\n|[a.go:1](a.go#L1)<br>none|\n",
+		},
+		{
+			name: "alignment",
+			table: func() *Table {
+				return NewTable(0, 4).SetAlignment(0, AlignLeft).SetAlignment(1, AlignCenter).
+					SetAlignment(2, AlignRight).SetAlignment(3, AlignDefault)
+			},
+			want: "|||||\n|:---|:---:|---:|----|\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.table().String(); got != tt.want {
+				t.Errorf("Table.String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package report
+
+import (
+	"encoding/csv"
+	"sort"
+	"strings"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/config"
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// Matrix crosses every threat of the library with every component. Each cell holds M, E, A and T when the threat is
+// respectively mitigated, exposed, accepted or transferred for the component, and is blank when it was never assessed.
+type Matrix struct {
+	Threats    []string `json:"threats"`
+	Components []string `json:"components"`
+	// Cells are indexed by threat then component.
+	Cells [][]string `json:"cells"`
+}
+
+// NewMatrix returns the threat × component coverage matrix of the library, with threats and components sorted by name.
+// The imported weaknesses and attack patterns the threat model does not reference are left out.
+func NewMatrix(l *library.Library) *Matrix {
+	m := &Matrix{}
+	for _, t := range l.AssessedThreats() {
+		m.Threats = append(m.Threats, t.Name)
+	}
+	for _, c := range l.Components {
+		m.Components = append(m.Components, c.Name)
+	}
+	sort.Strings(m.Threats)
+	sort.Strings(m.Components)
+
+	marks := map[string]map[string]string{}
+	mark := func(threat, component, letter string) {
+		if marks[threat] == nil {
+			marks[threat] = map[string]string{}
+		}
+		if !strings.Contains(marks[threat][component], letter) {
+			marks[threat][component] += letter
+		}
+	}
+
+	for _, mitigation := range l.ThreatModel.Mitigations {
+		mark(mitigation.Threat, mitigation.Component, "M")
+	}
+	for _, e := range l.ThreatModel.Exposures {
+		mark(e.Threat, e.Component, "E")
+	}
+	for _, a := range l.ThreatModel.Acceptances {
+		mark(a.Threat, a.Component, "A")
+	}
+	for _, t := range l.ThreatModel.Transfers {
+		mark(t.Threat, t.SourceComponent, "T")
+	}
+
+	m.Cells = make([][]string, len(m.Threats))
+	for i, threat := range m.Threats {
+		m.Cells[i] = make([]string, len(m.Components))
+		for j, component := range m.Components {
+			m.Cells[i][j] = marks[threat][component]
+		}
+	}
+
+	return m
+}
+
+// Markdown returns the matrix as a Markdown table, with a row per threat and a column per component.
+func (m *Matrix) Markdown() string {
+	t := NewTable(len(m.Threats), len(m.Components)+1)
+	t.SetTitle(0, "Threat")
+	for j, component := range m.Components {
+		t.SetTitle(j+1, component).SetAlignment(j+1, AlignCenter)
+	}
+
+	for i, threat := range m.Threats {
+		t.SetContent(i, 0, threat)
+		for j := range m.Components {
+			t.SetContent(i, j+1, m.Cells[i][j])
+		}
+	}
+
+	return t.String()
+}
+
+// CSV returns the matrix as RFC 4180 CSV, with a row per threat and a column per component, names neutralized like
+// csvCell.
+func (m *Matrix) CSV() (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+
+	if err := w.Write(append([]string{"threat"}, csvCells(m.Components)...)); err != nil {
+		return "", eris.Wrap(err, "failed to write csv header")
+	}
+	for i, threat := range m.Threats {
+		if err := w.Write(append([]string{csvCell(threat)}, m.Cells[i]...)); err != nil {
+			return "", eris.Wrap(err, "failed to write csv row")
+		}
+	}
+	w.Flush()
+
+	return b.String(), eris.Wrap(w.Error(), "failed to write csv")
+}
+
+// FileMatrix writes the threat × component coverage matrix of the threat model, in the csv or md format.
+func FileMatrix(format, filename string) error {
+	cfg, err := config.LoadFile()
+	if err != nil {
+		return err
+	}
+
+	l, err := readLibrary(cfg.ThreatModelDir)
+	if err != nil {
+		return err
+	}
+
+	m := NewMatrix(l)
+
+	var content string
+	switch format {
+	case "csv":
+		content, err = m.CSV()
+	case "md":
+		content = m.Markdown()
+	default:
+		err = eris.Errorf("unsupported matrix format %s", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(filename, content)
+}
+
+// csvReport returns the coverage matrix of the threat model as CSV.
+func csvReport(v *View) (string, error) {
+	return v.Matrix.CSV()
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestNewMatrix(t *testing.T) {
+	l := newLibrary(library.Threatmodel{
+		Mitigations: []library.Mitigate{
+			{Threat: "XSS", Component: "WebApp:Web", Control: "escaping"},
+			{Threat: "XSS", Component: "WebApp:Web", Control: "CSP"},
+		},
+		Exposures:   []library.Exposure{{Threat: "XSS", Component: "WebApp:Web"}, {Threat: "SQLi", Component: "WebApp:DB"}},
+		Acceptances: []library.Acceptance{{Threat: "SQLi", Component: "WebApp:DB"}},
+		Transfers:   []library.Transfer{{Threat: "DoS", SourceComponent: "WebApp:Web", DestinationComponent: "WebApp:CDN"}},
+	})
+
+	got := NewMatrix(l)
+	want := &Matrix{
+		Threats:    []string{"DoS", "SQLi", "XSS"},
+		Components: []string{"WebApp:CDN", "WebApp:DB", "WebApp:Web"},
+		Cells: [][]string{
+			{"", "", "T"},
+			{"", "EA", ""},
+			{"", "", "ME"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewMatrix() = %+v, want %+v", got, want)
+	}
+
+	tests := []struct {
+		name   string
+		output func() (string, error)
+		want   string
+	}{
+		{
+			name:   "markdown",
+			output: func() (string, error) { return got.Markdown(), nil },
+			want: "|Threat|WebApp:CDN|WebApp:DB|WebApp:Web|\n" +
+				"|----|:---:|:---:|:---:|\n" +
+				"|DoS|||T|\n" +
+				"|SQLi||EA||\n" +
+				"|XSS|||ME|\n",
+		},
+		{
+			name:   "csv",
+			output: got.CSV,
+			want:   "threat,WebApp:CDN,WebApp:DB,WebApp:Web\nDoS,,,T\nSQLi,,EA,\nXSS,,,ME\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.output()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != tt.want {
+				t.Errorf("output = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
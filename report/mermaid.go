@@ -0,0 +1,92 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// mermaidEscaper replaces the characters that would end or corrupt a quoted Mermaid label with their entity codes.
+var mermaidEscaper = strings.NewReplacer(`#`, `#35;`, `"`, `#quot;`, `<`, `#lt;`, `>`, `#gt;`)
+
+// Mermaid returns the data-flow diagram of the threat model as a Mermaid flowchart, which GitHub and
+// GitLab render natively in a mermaid code block. Exposed components are styled in red, mitigated ones in green,
+// components are grouped in their trust boundaries and the flows crossing a boundary are drawn in red.
+func Mermaid(l *library.Library) string {
+	d := newDiagram(l)
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	writeMermaidCluster(&b, d.root, 1)
+
+	var crossings []string
+	for i, e := range d.edges {
+		arrow := "-->"
+		switch {
+		case e.transfer:
+			arrow = "-.->"
+		case e.bidirectional:
+			arrow = "<-->"
+		}
+
+		if e.crossing {
+			crossings = append(crossings, strconv.Itoa(i))
+		}
+
+		if e.label == "" {
+			fmt.Fprintf(&b, "    %s %s %s\n", e.from.id, arrow, e.to.id)
+			continue
+		}
+		fmt.Fprintf(&b, "    %s %s|\"%s\"| %s\n", e.from.id, arrow, mermaidEscaper.Replace(e.label), e.to.id)
+	}
+
+	var exposed, mitigated []string
+	for _, n := range d.nodes {
+		switch {
+		case len(n.exposures) > 0:
+			exposed = append(exposed, n.id)
+		case len(n.mitigations) > 0:
+			mitigated = append(mitigated, n.id)
+		}
+	}
+
+	b.WriteString("    classDef exposed fill:#fdd,stroke:#c00\n")
+	b.WriteString("    classDef mitigated fill:#dfd,stroke:#080\n")
+	if len(exposed) > 0 {
+		fmt.Fprintf(&b, "    class %s exposed\n", strings.Join(exposed, ","))
+	}
+	if len(mitigated) > 0 {
+		fmt.Fprintf(&b, "    class %s mitigated\n", strings.Join(mitigated, ","))
+	}
+	if len(crossings) > 0 {
+		fmt.Fprintf(&b, "    linkStyle %s stroke:#c00,stroke-width:2px\n", strings.Join(crossings, ","))
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeMermaidCluster writes the nodes of a cluster followed by its nested clusters as subgraphs.
+func writeMermaidCluster(b *strings.Builder, c *cluster, depth int) {
+	indent := strings.Repeat("    ", depth)
+
+	for _, n := range c.nodes {
+		lines := []string{mermaidEscaper.Replace(n.name)}
+		for _, threat := range n.exposures {
+			lines = append(lines, "exposed to "+mermaidEscaper.Replace(threat))
+		}
+		for _, threat := range n.mitigations {
+			lines = append(lines, "mitigates "+mermaidEscaper.Replace(threat))
+		}
+
+		fmt.Fprintf(b, "%s%s[\"%s\"]\n", indent, n.id, strings.Join(lines, "<br/>"))
+	}
+
+	for _, child := range c.clusters {
+		fmt.Fprintf(b, "%ssubgraph %s[\"%s\"]\n", indent, child.id, mermaidEscaper.Replace(child.name))
+		writeMermaidCluster(b, child, depth+1)
+		fmt.Fprintf(b, "%send\n", indent)
+	}
+}
@@ -0,0 +1,111 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// newLibrary returns a library holding the threat model, with a component for each component it references.
+func newLibrary(tm library.Threatmodel) *library.Library {
+	l := &library.Library{
+		Components:  map[string]library.Component{},
+		Controls:    map[string]library.Control{},
+		Threats:     map[string]library.Threat{},
+		ThreatModel: tm,
+	}
+
+	add := func(names ...string) {
+		for _, name := range names {
+			elements := strings.Split(name, ":")
+			l.Components[name] = library.Component{Id: name, Name: name, Paths: [][]string{elements[:len(elements)-1]}}
+		}
+	}
+	for _, c := range tm.Connections {
+		add(c.SourceComponent, c.DestinationComponent)
+	}
+	for _, t := range tm.Transfers {
+		add(t.SourceComponent, t.DestinationComponent)
+		l.Threats[t.Threat] = library.Threat{Id: t.Threat, Name: t.Threat}
+	}
+	for _, e := range tm.Exposures {
+		add(e.Component)
+		l.Threats[e.Threat] = library.Threat{Id: e.Threat, Name: e.Threat}
+	}
+	for _, m := range tm.Mitigations {
+		add(m.Component)
+		l.Threats[m.Threat] = library.Threat{Id: m.Threat, Name: m.Threat}
+		l.Controls[m.Control] = library.Control{Id: m.Control, Name: m.Control}
+	}
+	for _, a := range tm.Acceptances {
+		add(a.Component)
+		l.Threats[a.Threat] = library.Threat{Id: a.Threat, Name: a.Threat}
+	}
+	for _, t := range tm.Tests {
+		add(t.Component)
+		l.Controls[t.Control] = library.Control{Id: t.Control, Name: t.Control}
+	}
+
+	return l
+}
+
+func TestMermaid(t *testing.T) {
+	tests := []struct {
+		name string
+		tm   library.Threatmodel
+		want []string
+	}{
+		{
+			name: "connection",
+			tm: library.Threatmodel{Connections: []library.Connection{
+				{SourceComponent: "User:Browser", DestinationComponent: "WebApp:Web", Direction: "to", Details: "HTTPS"},
+			}},
+			want: []string{
+				`    subgraph c0["User"]`,
+				`        n0["User:Browser"]`,
+				`    n0 -->|"HTTPS"| n1`,
+				`    linkStyle 0 stroke:#c00,stroke-width:2px`,
+			},
+		},
+		{
+			name: "quotes and brackets",
+			tm: library.Threatmodel{
+				Connections: []library.Connection{
+					{SourceComponent: `Web "front" [v2]`, DestinationComponent: "DB <primary>", Direction: "with", Details: `say "hi" #1`},
+				},
+				Exposures: []library.Exposure{{Threat: `XSS "stored"`, Component: "DB <primary>"}},
+			},
+			want: []string{
+				`    n0["DB #lt;primary#gt;<br/>exposed to XSS #quot;stored#quot;"]`,
+				`    n1["Web #quot;front#quot; [v2]"]`,
+				`    n1 <-->|"say #quot;hi#quot; #35;1"| n0`,
+				`    class n0 exposed`,
+			},
+		},
+		{
+			name: "transfer",
+			tm: library.Threatmodel{Transfers: []library.Transfer{
+				{Threat: "DoS", SourceComponent: "WebApp:Web", DestinationComponent: "WebApp:CDN"},
+			}},
+			want: []string{
+				`    subgraph c0["WebApp"]`,
+				`    n1 -.->|"DoS"| n0`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Mermaid(newLibrary(tt.tm))
+			if !strings.HasPrefix(got, "flowchart LR\n") {
+				t.Errorf("Mermaid() = %q, want a flowchart", got)
+			}
+			for _, line := range tt.want {
+				if !strings.Contains(got+"\n", line+"\n") {
+					t.Errorf("Mermaid() = %s\nwant line %q", got, line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package report
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// otmUnrated is the rating of the trust zones, the likelihood and impact of the threats and the risk reduction of the
+// mitigations in the export: annotations do not rate them, so every rating is neutral.
+const otmUnrated = 50
+
+// otmStates maps the status of a threat against a component to the state of the threat instance.
+var otmStates = map[string]string{
+	StatusExposed:     "EXPOSED",
+	StatusAccepted:    "EXPOSED",
+	StatusUntested:    "MITIGATED",
+	StatusTransferred: "NOT_APPLICABLE",
+	StatusMitigated:   "MITIGATED",
+}
+
+// otmReport returns the threat model as an Open Threat Model document: components nested in the trust zones of their
+// path, connections as dataflows, threats and controls as threats and mitigations, and the annotations relating them as
+// threat and mitigation instances of the components.
+func otmReport(v *View) (string, error) {
+	otm := library.OTM{
+		OTMVersion: library.OTMVersion,
+		Project: library.OTMProject{
+			Name:        v.Project.Name,
+			ID:          slug(v.Project.Name),
+			Description: v.Project.Description,
+		},
+		TrustZones:  []library.OTMTrustZone{},
+		Components:  []library.OTMComponent{},
+		Dataflows:   []library.OTMDataflow{},
+		Threats:     []library.OTMThreat{},
+		Mitigations: []library.OTMMitigation{},
+	}
+
+	// The annotations reference the library entries by name, and the document by id.
+	threatID := func(name string) string {
+		if t, ok := v.Library.FindThreat(name); ok {
+			return t.Id
+		}
+
+		return name
+	}
+	controlID := func(name string) string {
+		if c, ok := v.Library.FindControl(name); ok {
+			return c.Id
+		}
+
+		return name
+	}
+	componentID := func(name string) string {
+		if c, ok := v.Library.FindComponent(name); ok {
+			return c.Id
+		}
+
+		return name
+	}
+
+	instances := map[string][]library.OTMThreatInstance{}
+	for _, cp := range v.Postures {
+		for _, p := range cp.Threats {
+			instance := library.OTMThreatInstance{Threat: threatID(p.Threat), State: otmStates[p.Status]}
+			for _, m := range p.Mitigations {
+				state := "IMPLEMENTED"
+				if len(testsFor(m, v.ThreatModel.Tests)) == 0 {
+					state = "RECOMMENDED"
+				}
+				instance.Mitigations = append(instance.Mitigations, library.OTMMitigationInstance{Mitigation: controlID(m.Control), State: state})
+			}
+			instances[cp.Component] = append(instances[cp.Component], instance)
+		}
+	}
+
+	zones := map[string]bool{}
+	for _, name := range sortedNames(v.Library.Components) {
+		path := componentPath(v.Library, name)
+
+		parent := library.OTMDefaultZone
+		for i := range path {
+			id := "zone:" + strings.Join(path[:i+1], ":")
+			if !zones[id] {
+				zones[id] = true
+				zone := library.OTMTrustZone{ID: id, Name: path[i], Risk: library.OTMTrustRisk{TrustRating: otmUnrated}}
+				if i > 0 {
+					zone.Parent = &library.OTMParent{TrustZone: parent}
+				}
+				otm.TrustZones = append(otm.TrustZones, zone)
+			}
+			parent = id
+		}
+		if parent == library.OTMDefaultZone && !zones[parent] {
+			zones[parent] = true
+			otm.TrustZones = append(otm.TrustZones, library.OTMTrustZone{
+				ID: parent, Name: "Default", Risk: library.OTMTrustRisk{TrustRating: otmUnrated},
+			})
+		}
+
+		c := v.Library.Components[name]
+		otm.Components = append(otm.Components, library.OTMComponent{
+			ID:          c.Id,
+			Name:        strings.TrimPrefix(c.Name, strings.Join(path, ":")+":"),
+			Type:        "generic",
+			Description: c.Description,
+			Parent:      library.OTMParent{TrustZone: parent},
+			Threats:     instances[c.Name],
+		})
+	}
+
+	for i, c := range v.ThreatModel.Connections {
+		otm.Dataflows = append(otm.Dataflows, library.OTMDataflow{
+			ID:            "dataflow:" + strconv.Itoa(i+1),
+			Name:          c.Details,
+			Bidirectional: c.Direction == "with",
+			Source:        componentID(c.SourceComponent),
+			Destination:   componentID(c.DestinationComponent),
+		})
+	}
+
+	for _, name := range sortedNames(v.Threats) {
+		t := v.Threats[name]
+		otm.Threats = append(otm.Threats, library.OTMThreat{
+			ID:          t.Id,
+			Name:        t.Name,
+			Description: t.Description,
+			Categories:  []string{},
+			Risk:        library.OTMThreatRisk{Likelihood: otmUnrated, Impact: otmUnrated},
+		})
+	}
+
+	for _, name := range sortedNames(v.Library.Controls) {
+		c := v.Library.Controls[name]
+		otm.Mitigations = append(otm.Mitigations, library.OTMMitigation{
+			ID:            c.Id,
+			Name:          c.Name,
+			Description:   c.Description,
+			RiskReduction: otmUnrated,
+		})
+	}
+
+	b, err := json.MarshalIndent(otm, "", " ")
+	if err != nil {
+		return "", eris.Wrap(err, "failed to marshal Open Threat Model")
+	}
+
+	return string(b) + "\n", nil
+}
+
+// sortedNames returns the keys of an index of the library, sorted.
+func sortedNames[T any](index map[string]T) []string {
+	names := make([]string, 0, len(index))
+	for name := range index {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestOTMReport(t *testing.T) {
+	l := newLibrary(library.Threatmodel{
+		Connections: []library.Connection{{SourceComponent: "Internet:User:Browser", DestinationComponent: "Internet:DMZ:Web", Direction: "with", Details: "HTTPS"}},
+		Exposures:   []library.Exposure{{Threat: "SQLi", Component: "DB"}},
+		Mitigations: []library.Mitigate{{Threat: "XSS", Component: "Internet:DMZ:Web", Control: "escaping"}},
+	})
+	v := newView(l)
+
+	out, err := otmReport(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var otm library.OTM
+	if err := json.Unmarshal([]byte(out), &otm); err != nil {
+		t.Fatal(err)
+	}
+
+	var zones []string
+	for _, z := range otm.TrustZones {
+		parent := ""
+		if z.Parent != nil {
+			parent = z.Parent.TrustZone
+		}
+		zones = append(zones, z.ID+" < "+parent)
+	}
+	wantZones := []string{"default < ", "zone:Internet < ", "zone:Internet:DMZ < zone:Internet", "zone:Internet:User < zone:Internet"}
+	if !reflect.DeepEqual(zones, wantZones) {
+		t.Errorf("otmReport() trust zones = %v, want %v", zones, wantZones)
+	}
+	if len(otm.Dataflows) != 1 || !otm.Dataflows[0].Bidirectional || otm.Dataflows[0].Source != "Internet:User:Browser" {
+		t.Errorf("otmReport() dataflows = %+v, want the bidirectional HTTPS flow", otm.Dataflows)
+	}
+	for _, c := range otm.Components {
+		if c.ID == "Internet:DMZ:Web" && (c.Name != "Web" || len(c.Threats) != 1 || c.Threats[0].State != "MITIGATED" ||
+			c.Threats[0].Mitigations[0].State != "RECOMMENDED") {
+			t.Errorf("otmReport() component = %+v, want the untested mitigation of XSS", c)
+		}
+		if c.ID == "DB" && (c.Parent.TrustZone != library.OTMDefaultZone || len(c.Threats) != 1 || c.Threats[0].State != "EXPOSED") {
+			t.Errorf("otmReport() component = %+v, want DB exposed in the default zone", c)
+		}
+	}
+
+	// The export imports back into the same components.
+	filename := filepath.Join(t.TempDir(), "report.otm")
+	if err := os.WriteFile(filename, []byte(out), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	imported := &library.Library{Components: map[string]library.Component{}, Controls: map[string]library.Control{}, Threats: map[string]library.Threat{}}
+	if err := imported.ImportOTM(filename); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sortedNames(imported.Components), sortedNames(l.Components); !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportOTM(otmReport()) components = %v, want %v", got, want)
+	}
+	if got, want := sortedNames(imported.Threats), sortedNames(l.Threats); !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportOTM(otmReport()) threats = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,172 @@
+package report
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// Statuses of a threat against a component, from the highest to the lowest risk.
+const (
+	StatusExposed     = "exposed and unmitigated"
+	StatusAccepted    = "accepted"
+	StatusUntested    = "mitigated but untested"
+	StatusTransferred = "transferred"
+	StatusMitigated   = "mitigated and tested"
+)
+
+// statusRanks orders the statuses from the highest to the lowest risk.
+var statusRanks = map[string]int{
+	StatusExposed:     0,
+	StatusAccepted:    1,
+	StatusUntested:    2,
+	StatusTransferred: 3,
+	StatusMitigated:   4,
+}
+
+type (
+	// ThreatPosture is the status of a threat against a component with the annotations it is computed from.
+	ThreatPosture struct {
+		Threat      string               `json:"threat"`
+		Status      string               `json:"status"`
+		Controls    []string             `json:"controls"`
+		Exposures   []library.Exposure   `json:"exposures"`
+		Mitigations []library.Mitigate   `json:"mitigations"`
+		Acceptances []library.Acceptance `json:"acceptances"`
+		Transfers   []library.Transfer   `json:"transfers"`
+	}
+	// ComponentPosture is the risk posture of a component: the status of every threat touching it, residual risks first.
+	ComponentPosture struct {
+		Component string          `json:"component"`
+		Threats   []ThreatPosture `json:"threats"`
+		// Residual counts the threats the component is still exposed to or which were accepted.
+		Residual int `json:"residual"`
+	}
+)
+
+// Residual reports whether the threat is a residual risk of the component: exposed and unmitigated, or accepted.
+func (p ThreatPosture) Residual() bool {
+	return p.Status == StatusExposed || p.Status == StatusAccepted
+}
+
+// postures returns the risk posture of every component touched by a threat, the riskiest components first.
+func postures(l *library.Library) []ComponentPosture {
+	threats := map[string]map[string]*ThreatPosture{}
+	get := func(component, threat string) *ThreatPosture {
+		if threats[component] == nil {
+			threats[component] = map[string]*ThreatPosture{}
+		}
+		if threats[component][threat] == nil {
+			threats[component][threat] = &ThreatPosture{Threat: threat}
+		}
+
+		return threats[component][threat]
+	}
+
+	for _, e := range l.ThreatModel.Exposures {
+		p := get(e.Component, e.Threat)
+		p.Exposures = append(p.Exposures, e)
+	}
+	for _, m := range l.ThreatModel.Mitigations {
+		p := get(m.Component, m.Threat)
+		p.Mitigations = append(p.Mitigations, m)
+	}
+	for _, a := range l.ThreatModel.Acceptances {
+		p := get(a.Component, a.Threat)
+		p.Acceptances = append(p.Acceptances, a)
+	}
+	for _, t := range l.ThreatModel.Transfers {
+		p := get(t.SourceComponent, t.Threat)
+		p.Transfers = append(p.Transfers, t)
+	}
+
+	result := make([]ComponentPosture, 0, len(threats))
+	for component, byThreat := range threats {
+		cp := ComponentPosture{Component: component}
+		for _, p := range byThreat {
+			p.Status = status(p, l.ThreatModel.Tests)
+			for _, m := range p.Mitigations {
+				p.Controls = append(p.Controls, m.Control)
+			}
+
+			cp.Threats = append(cp.Threats, *p)
+			if p.Residual() {
+				cp.Residual++
+			}
+		}
+
+		sort.Slice(cp.Threats, func(i, j int) bool {
+			a, b := cp.Threats[i], cp.Threats[j]
+			if statusRanks[a.Status] != statusRanks[b.Status] {
+				return statusRanks[a.Status] < statusRanks[b.Status]
+			}
+
+			return a.Threat < b.Threat
+		})
+		result = append(result, cp)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Residual != result[j].Residual {
+			return result[i].Residual > result[j].Residual
+		}
+
+		return result[i].Component < result[j].Component
+	})
+
+	return result
+}
+
+// status computes the status of a threat against a component. Mitigations take precedence over acceptances,
+// which take precedence over transfers; a mitigated threat is tested when the controls of all its mitigations are.
+func status(p *ThreatPosture, tests []library.Test) string {
+	switch {
+	case len(p.Mitigations) > 0:
+		for _, m := range p.Mitigations {
+			if len(testsFor(m, tests)) == 0 {
+				return StatusUntested
+			}
+		}
+
+		return StatusMitigated
+	case len(p.Acceptances) > 0:
+		return StatusAccepted
+	case len(p.Transfers) > 0:
+		return StatusTransferred
+	default:
+		return StatusExposed
+	}
+}
+
+// postureTable returns the risk posture of a component as a Markdown table.
+func (v *View) postureTable(cp ComponentPosture) string {
+	t := newRecordTable(len(cp.Threats), "Threat", "Status", "Controls", "Sources")
+	for row, p := range cp.Threats {
+		var links []string
+		for _, e := range p.Exposures {
+			links = append(links, v.sourceLink(e.Source))
+		}
+		for _, m := range p.Mitigations {
+			links = append(links, v.sourceLink(m.Source))
+		}
+		for _, a := range p.Acceptances {
+			links = append(links, v.sourceLink(a.Source))
+		}
+		for _, tr := range p.Transfers {
+			links = append(links, v.sourceLink(tr.Source))
+		}
+
+		status := p.Status
+		if p.Residual() {
+			status = "**" + status + "**"
+		}
+
+		t.SetContent(row, 0, p.Threat).
+			SetRawContent(row, 1, status).
+			SetContent(row, 2, strings.Join(p.Controls, ", ")).
+			SetRawContent(row, 3, strings.Join(links, "<br>"))
+	}
+
+	return t.String()
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestStatus(t *testing.T) {
+	escaping := library.Mitigate{Threat: "XSS", Component: "WebApp:Web", Control: "escaping"}
+	csp := library.Mitigate{Threat: "XSS", Component: "WebApp:Web", Control: "CSP"}
+	tests := []library.Test{{Control: "escaping", Component: "WebApp:Web"}}
+
+	cases := []struct {
+		name    string
+		posture ThreatPosture
+		want    string
+	}{
+		{name: "exposed", posture: ThreatPosture{Exposures: []library.Exposure{{}}}, want: StatusExposed},
+		{name: "accepted", posture: ThreatPosture{Exposures: []library.Exposure{{}}, Acceptances: []library.Acceptance{{}}}, want: StatusAccepted},
+		{name: "transferred", posture: ThreatPosture{Transfers: []library.Transfer{{}}}, want: StatusTransferred},
+		{name: "accepted over transferred", posture: ThreatPosture{Acceptances: []library.Acceptance{{}}, Transfers: []library.Transfer{{}}}, want: StatusAccepted},
+		{name: "mitigated and tested", posture: ThreatPosture{Mitigations: []library.Mitigate{escaping}}, want: StatusMitigated},
+		{name: "mitigated over accepted", posture: ThreatPosture{Mitigations: []library.Mitigate{escaping}, Acceptances: []library.Acceptance{{}}}, want: StatusMitigated},
+		{name: "untested control", posture: ThreatPosture{Mitigations: []library.Mitigate{csp}}, want: StatusUntested},
+		{name: "one untested control", posture: ThreatPosture{Mitigations: []library.Mitigate{escaping, csp}}, want: StatusUntested},
+		{name: "tested on another component", posture: ThreatPosture{Mitigations: []library.Mitigate{{Threat: "XSS", Component: "WebApp:API", Control: "escaping"}}}, want: StatusUntested},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status(&tt.posture, tests); got != tt.want {
+				t.Errorf("status() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostures(t *testing.T) {
+	l := newLibrary(library.Threatmodel{
+		Mitigations: []library.Mitigate{{Threat: "XSS", Component: "WebApp:Web", Control: "escaping"}},
+		Exposures: []library.Exposure{
+			{Threat: "XSS", Component: "WebApp:Web"},
+			{Threat: "SQLi", Component: "WebApp:DB"},
+			{Threat: "DoS", Component: "WebApp:Web"},
+		},
+		Transfers: []library.Transfer{{Threat: "DoS", SourceComponent: "WebApp:Web", DestinationComponent: "WebApp:CDN"}},
+	})
+
+	got := postures(l)
+	if len(got) != 2 {
+		t.Fatalf("postures() = %+v, want 2 components", got)
+	}
+	if got[0].Component != "WebApp:DB" || got[0].Residual != 1 {
+		t.Errorf("postures()[0] = %+v, want the exposed WebApp:DB first", got[0])
+	}
+
+	web := got[1]
+	var statuses []string
+	for _, p := range web.Threats {
+		statuses = append(statuses, p.Threat+": "+p.Status)
+	}
+	want := []string{"XSS: " + StatusUntested, "DoS: " + StatusTransferred}
+	if web.Component != "WebApp:Web" || web.Residual != 0 || len(statuses) != len(want) || statuses[0] != want[0] || statuses[1] != want[1] {
+		t.Errorf("postures()[1] = %s %v, want WebApp:Web %v", web.Component, statuses, want)
+	}
+}
@@ -2,124 +2,301 @@ package report
 
 import (
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/rotisserie/eris"
 
+	"github.com/morphysm/famed-annotated/config"
 	"github.com/morphysm/famed-annotated/library"
 )
 
-func FileReport() error {
-	report, err := report()
-	if err != nil {
-		return eris.Wrap(err, "failed to retrieve report")
+// Options configures the generation of the report.
+type Options struct {
+	// Formats are the formats of the report, keys of formats.
+	Formats []string
+	// Output is the file the report is written to, - for the standard output. With several formats, the extension of
+	// each format replaces its own, and the directory formats are written to the output named after them, like
+	// report-site. It defaults to the filename of each format in the current directory.
+	Output string
+	// ThreatModelDir is the directory the threat model is read from, overrides the configuration.
+	ThreatModelDir string
+	// Templates are text/template files executed instead of the default Markdown template.
+	Templates []string
+	// Generated is the date of the report.
+	Generated time.Time
+}
+
+// formats associates the supported report formats with the file they are written to by default and their generator.
+// Formats written to a directory generate pages, indexed by their path in the directory, instead. Only the formats
+// embedding the diagram have Graphviz render it.
+var formats = map[string]struct {
+	filename string
+	generate func(v *View) (string, error)
+	pages    func(v *View) (map[string]string, error)
+	diagram  bool
+}{
+	"md":    {filename: "report.md", generate: report, diagram: true},
+	"html":  {filename: "report.html", generate: htmlReport, diagram: true},
+	"json":  {filename: "report.json", generate: jsonReport},
+	"csv":   {filename: "report.csv", generate: csvReport},
+	"sarif": {filename: "report.sarif", generate: sarifReport},
+	"otm":   {filename: "report.otm", generate: otmReport},
+	"junit": {filename: "report.junit.xml", generate: junitReport},
+	// The Threat Dragon model is JSON too, and keeps the full extension apart from the json report.
+	"threatdragon": {filename: "report.threatdragon.json", generate: threatDragonReport},
+	"site":         {filename: "site", pages: sitePages, diagram: true},
+	"threatspec":   {filename: "threatspec", pages: threatspecPages},
+}
+
+// FileReport generates the report of the threat model in every requested format and writes them. Custom templates
+// replace the default Markdown template, each is written next to the output, named after the template without
+// its .tmpl extension, unless it is the only output requested.
+func FileReport(opts Options) error {
+	for _, format := range opts.Formats {
+		if _, ok := formats[format]; !ok {
+			return eris.Errorf("unsupported report format %s", format)
+		}
 	}
 
-	err = os.WriteFile("report.md", []byte(report), 0o600)
+	cfg, err := config.LoadFile()
 	if err != nil {
-		return eris.Wrap(err, "failed to write file")
+		return err
+	}
+	if opts.ThreatModelDir != "" {
+		cfg.ThreatModelDir = opts.ThreatModelDir
 	}
 
-	return nil
-}
+	templates := opts.Templates
+	if len(templates) == 0 {
+		templates = cfg.Report.Templates
+	}
 
-// @exposes tmpl:Execute to XSS injection with insufficient input validation
-// @threat SQL Injection (#sqli)
+	type output struct {
+		filename string
+		generate func(v *View) (string, error)
+		pages    func(v *View) (map[string]string, error)
+		diagram  bool
+	}
 
-func report() (string, error) {
-	md := NewMarkdown()
+	var outputs []output
+	for _, format := range opts.Formats {
+		f := formats[format]
+		if format != "md" || len(templates) == 0 {
+			outputs = append(outputs, output{filename: destination(opts.Output, f.filename, len(opts.Formats) > 1), generate: f.generate, pages: f.pages, diagram: f.diagram})
+			continue
+		}
 
-	md.WriteTitle("famed-annotated report "+time.Now().Format(time.RFC822), 1)
+		for _, filename := range templates {
+			filename := filename
+			dest := filepath.Join(filepath.Dir(destination(opts.Output, f.filename, true)), strings.TrimSuffix(filepath.Base(filename), ".tmpl"))
+			if len(opts.Formats) == 1 && len(templates) == 1 && opts.Output != "" {
+				dest = opts.Output
+			}
+			if samePath(dest, filename) {
+				return eris.Errorf("the report of template %s would overwrite it, name the template with a .tmpl extension or set --output", filename)
+			}
+
+			outputs = append(outputs, output{filename: dest, generate: func(v *View) (string, error) {
+				text, err := os.ReadFile(filename)
+				if err != nil {
+					return "", eris.Wrapf(err, "failed to read template %s", filename)
+				}
+
+				return executeTemplate(filepath.Base(filename), string(text), v)
+			}, diagram: true})
+		}
+	}
 
-	l := &library.Library{
-		Components: map[string]library.Component{},
-		Controls:   map[string]library.Control{},
-		Threats:    map[string]library.Threat{},
+	if opts.Output == "-" && len(outputs) > 1 {
+		return eris.New("only one report can be written to the standard output")
 	}
-	err := l.ReadFiles()
+	written := map[string]bool{}
+	for _, o := range outputs {
+		if o.pages != nil && o.filename == "-" {
+			return eris.New("a site cannot be written to the standard output")
+		}
+		if o.filename != "-" && written[filepath.Clean(o.filename)] {
+			return eris.Errorf("several reports would be written to %s, set another --output or template name", o.filename)
+		}
+		written[filepath.Clean(o.filename)] = true
+	}
+
+	l, err := readLibrary(cfg.ThreatModelDir)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	md.WriteTitle("Diagram", 2)
+	v, err := NewView(l, cfg, opts.Generated)
+	if err != nil {
+		return eris.Wrap(err, "failed to retrieve report")
+	}
 
-	if len(l.ThreatModel.Exposures) >= 1 {
-		md.WriteTitle("Exposures", 2)
-		for _, item := range l.ThreatModel.Exposures {
-			md.Writeln()
+	var image string
+	for _, o := range outputs {
+		if o.diagram {
+			if image, err = Graphviz(l, cfg.ThreatModelDir); err != nil {
+				return err
+			}
 
-			md.WriteTitle(item.Component+" against "+item.Details, 3)
-			md.write(item.Threat)
+			break
 		}
-
-		md.Writeln()
 	}
+	v.imagePath = image
 
-	if len(l.ThreatModel.Mitigations) >= 1 {
-		md.WriteTitle("Mitigations", 2)
-		for _, item := range l.ThreatModel.Mitigations {
-			md.Writeln()
-
-			md.WriteTitle(item.Threat+" against "+item.Component+" mitigated by "+item.Control, 3)
+	for _, o := range outputs {
+		// The diagram is linked relatively to the report, or to the root of the site.
+		dir := filepath.Dir(o.filename)
+		if o.pages != nil {
+			dir = o.filename
+		}
+		v.outputDir = dir
+		if image != "" && o.filename != "-" {
+			if rel, err := filepath.Rel(dir, image); err == nil {
+				v.Image = filepath.ToSlash(rel)
+			}
 		}
 
-		md.Writeln()
-	}
+		if o.pages != nil {
+			pages, err := o.pages(v)
+			if err != nil {
+				return eris.Wrap(err, "failed to retrieve report")
+			}
+			v.Image = image
 
-	if len(l.ThreatModel.Reviews) >= 1 {
-		md.WriteTitle("Reviews", 2)
-		for _, item := range l.ThreatModel.Reviews {
-			md.Writeln()
+			if err := writePages(o.filename, pages); err != nil {
+				return err
+			}
 
-			md.WriteTitle(item.Details, 3)
-			md.Write(item.Component)
+			continue
 		}
 
-		md.Writeln()
+		report, err := o.generate(v)
+		if err != nil {
+			return eris.Wrap(err, "failed to retrieve report")
+		}
+		v.Image = image
+
+		if err := writeOutput(o.filename, report); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// destination returns the file a report is written to. With several formats, the extension of the default filename
+// of the format replaces the one of the output, and the name of the directory of a format written to one is appended
+// to the output, like report-site.
+func destination(output, filename string, several bool) string {
+	switch {
+	case output == "":
+		return filename
+	case output == "-" || !several:
+		return output
+	case extension(filename) == "":
+		return strings.TrimSuffix(output, filepath.Ext(output)) + "-" + filename
+	default:
+		return strings.TrimSuffix(output, filepath.Ext(output)) + extension(filename)
 	}
+}
 
-	if len(l.ThreatModel.Connections) >= 1 {
-		md.WriteTitle("Connections", 2)
-		for _, item := range l.ThreatModel.Connections {
-			md.Writeln()
+// extension returns the extension of a filename from its first dot, like .threatdragon.json.
+func extension(filename string) string {
+	if i := strings.Index(filename, "."); i >= 0 {
+		return filename[i:]
+	}
 
-			md.WriteTitle(item.Details+" To "+item.DestinationComponent, 3)
+	return ""
+}
 
-			md.Write(item.SourceComponent)
+// writeOutput writes content to filename, or to the standard output if filename is -.
+func writeOutput(filename, content string) error {
+	if filename == "-" {
+		if _, err := os.Stdout.WriteString(content); err != nil {
+			return eris.Wrap(err, "failed to write to the standard output")
 		}
 
-		md.Writeln()
+		return nil
+	}
+
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil { //nolint:gosec // reports are meant to be shared
+		return eris.Wrapf(err, "failed to write %s", filename)
 	}
 
-	md.Writeln()
+	return nil
+}
 
-	md.Writeln()
-	md.WriteTitle("Components", 2)
-	for _, component := range l.Components {
-		md.Writeln()
+// samePath reports whether two paths name the same file.
+func samePath(a, b string) bool {
+	a, errA := filepath.Abs(a)
+	b, errB := filepath.Abs(b)
+
+	return errA == nil && errB == nil && a == b
+}
 
-		md.WriteTitle(component.Name, 3)
+// pagesManifest lists the pages written in a directory, so that the ones which are not generated anymore are removed.
+const pagesManifest = ".famed-annotated-pages"
+
+// writePages writes each page to its path in the directory, and removes the pages it wrote there before which are
+// not part of the pages anymore.
+func writePages(dir string, pages map[string]string) error {
+	manifest := filepath.Join(dir, pagesManifest)
+
+	// Remove the pages of the previous generation which are gone, like the page of a removed component.
+	if previous, err := os.ReadFile(manifest); err == nil {
+		for _, path := range strings.Split(string(previous), "\n") {
+			if _, ok := pages[path]; ok || path == "" || filepath.IsAbs(path) || strings.Contains(path, "..") {
+				continue
+			}
+
+			filename := filepath.Join(dir, filepath.FromSlash(path))
+			if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+				return eris.Wrapf(err, "failed to remove %s", filename)
+			}
+			_ = os.Remove(filepath.Dir(filename)) // only removed when left empty
+		}
 	}
 
-	md.Writeln()
-	md.WriteTitle("Controls", 2)
-	for _, control := range l.Controls {
-		md.Writeln()
+	paths := make([]string, 0, len(pages))
+	for path, content := range pages {
+		paths = append(paths, path)
+
+		filename := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil { //nolint:gosec // reports are meant to be shared
+			return eris.Wrapf(err, "failed to create %s", filepath.Dir(filename))
+		}
 
-		md.WriteTitle(control.Name, 3)
-		md.Write(control.Id)
-		md.Write(control.Description)
+		if err := writeOutput(filename, content); err != nil {
+			return err
+		}
 	}
+	sort.Strings(paths)
 
-	md.Writeln()
-	md.WriteTitle("Threats", 2)
-	for _, threat := range l.Threats {
-		md.Writeln()
+	return writeOutput(manifest, strings.Join(paths, "\n")+"\n")
+}
+
+// readLibrary reads the library and the threat model saved by run in the threat model directory.
+func readLibrary(dir string) (*library.Library, error) {
+	l := &library.Library{
+		Components: map[string]library.Component{},
+		Controls:   map[string]library.Control{},
+		Threats:    map[string]library.Threat{},
+	}
 
-		md.WriteTitle(threat.Name, 3)
-		md.Write(threat.Id)
-		md.Write(threat.Description)
+	if err := l.ReadFiles(dir); err != nil {
+		return nil, eris.Wrap(err, "failed to retrieve report")
 	}
 
-	return md.String(), nil
+	return l, nil
+}
+
+// @exposes tmpl:Execute to XSS injection with insufficient input validation
+// @threat SQL Injection (#sqli)
+
+// report returns the threat model as a Markdown document, generated with the embedded default template.
+func report(v *View) (string, error) {
+	return executeTemplate("report.md.tmpl", markdownTemplate, v)
 }
@@ -0,0 +1,287 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morphysm/famed-annotated/config"
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// chdir changes to the directory for the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+// newProject creates a project holding the library in a temporary directory, and changes to it for the test.
+func newProject(t *testing.T, l *library.Library) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	if err := config.NewDefault(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SaveFiles(config.DefaultThreatModelDir); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestFileReportDiagram(t *testing.T) {
+	tm := library.Threatmodel{Connections: []library.Connection{
+		{SourceComponent: "User:Browser", DestinationComponent: "WebApp:Web", Direction: "to", Details: "HTTPS"},
+	}}
+
+	tests := []struct {
+		format string
+		want   bool
+	}{
+		{format: "md", want: true},
+		{format: "html", want: true},
+		{format: "site", want: true},
+		{format: "json"},
+		{format: "csv"},
+		{format: "sarif"},
+		{format: "otm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			dir := newProject(t, newLibrary(tm))
+
+			if err := FileReport(Options{Formats: []string{tt.format}, Generated: time.Unix(0, 0).UTC()}); err != nil {
+				t.Fatal(err)
+			}
+			_, err := os.Stat(filepath.Join(dir, config.DefaultThreatModelDir, dotFile))
+			if got := err == nil; got != tt.want {
+				t.Errorf("FileReport() wrote the diagram: %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileReportReproducible(t *testing.T) {
+	tm := library.Threatmodel{
+		Mitigations: []library.Mitigate{{Threat: "XSS", Component: "WebApp:Web", Control: "escaping"}},
+		Exposures:   []library.Exposure{{Threat: "SQLi", Component: "WebApp:DB", Details: "raw queries"}},
+		Connections: []library.Connection{{SourceComponent: "User:Browser", DestinationComponent: "WebApp:Web", Direction: "to", Details: "HTTPS"}},
+	}
+
+	var names []string
+	for format := range formats {
+		names = append(names, format)
+	}
+
+	var reports []map[string]string
+	for i := 0; i < 2; i++ {
+		dir := newProject(t, newLibrary(tm))
+		if err := os.Mkdir("out", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := FileReport(Options{Formats: names, Output: "out/report", Generated: time.Unix(1, 0).UTC()}); err != nil {
+			t.Fatal(err)
+		}
+
+		files := map[string]string{}
+		err := filepath.Walk(filepath.Join(dir, "out"), func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			files[strings.TrimPrefix(path, dir)] = string(data)
+
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		reports = append(reports, files)
+	}
+
+	if len(reports[0]) == 0 {
+		t.Fatal("FileReport() wrote no report")
+	}
+	for name, content := range reports[0] {
+		if reports[1][name] != content {
+			t.Errorf("FileReport() wrote %s = %q, then %q", name, content, reports[1][name])
+		}
+	}
+}
+
+// fakeDot puts a dot binary on the PATH for the test, which renders every diagram as the same SVG image.
+func fakeDot(t *testing.T) {
+	t.Helper()
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\nwhile [ $# -gt 0 ]; do [ \"$1\" = -o ] && out=$2; shift; done\necho '<?xml version=\"1.0\"?><svg id=\"fake\"></svg>' > \"$out\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "dot"), []byte(script), 0o700); err != nil { //nolint:gosec // executed by the test
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestDestination(t *testing.T) {
+	tests := []struct {
+		output   string
+		filename string
+		several  bool
+		want     string
+	}{
+		{output: "", filename: "report.md", want: "report.md"},
+		{output: "", filename: "site", several: true, want: "site"},
+		{output: "-", filename: "report.md", several: true, want: "-"},
+		{output: "out/threats.txt", filename: "report.md", want: "out/threats.txt"},
+		{output: "out/threats", filename: "site", want: "out/threats"},
+		{output: "out/report.md", filename: "report.html", several: true, want: "out/report.html"},
+		{output: "out/report", filename: "report.threatdragon.json", several: true, want: "out/report.threatdragon.json"},
+		{output: "out/report.md", filename: "site", several: true, want: "out/report-site"},
+		{output: "out/report.md", filename: "threatspec", several: true, want: "out/report-threatspec"},
+	}
+
+	for _, tt := range tests {
+		if got := destination(tt.output, tt.filename, tt.several); got != tt.want {
+			t.Errorf("destination(%q, %q, %v) = %q, want %q", tt.output, tt.filename, tt.several, got, tt.want)
+		}
+	}
+}
+
+func TestFileReportDestinations(t *testing.T) {
+	tm := library.Threatmodel{
+		Exposures:   []library.Exposure{{Threat: "XSS", Component: "WebApp:Web"}},
+		Connections: []library.Connection{{SourceComponent: "User:Browser", DestinationComponent: "WebApp:Web", Direction: "to", Details: "HTTPS"}},
+	}
+
+	tests := []struct {
+		name    string
+		formats []string
+		output  string
+		want    []string
+		wantErr bool
+	}{
+		{name: "defaults", formats: []string{"md", "site", "threatspec"}, want: []string{"report.md", "site/index.md", "threatspec/threatmodel.json"}},
+		{
+			name:    "directory formats",
+			formats: []string{"md", "site", "threatspec"},
+			output:  "out/report.md",
+			want:    []string{"out/report.md", "out/report-site/index.md", "out/report-threatspec/threatmodel.json"},
+		},
+		{name: "same format twice", formats: []string{"json", "json"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newProject(t, newLibrary(tm))
+			if err := os.Mkdir("out", 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := FileReport(Options{Formats: tt.formats, Output: tt.output, Generated: time.Unix(0, 0).UTC()})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FileReport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, name := range tt.want {
+				if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+					t.Errorf("FileReport() did not write %s: %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestFileReportImage(t *testing.T) {
+	fakeDot(t)
+
+	tests := []struct {
+		format string
+		output string
+		want   string
+	}{
+		{format: "html", output: "report.html", want: `<svg id="fake"></svg>`},
+		{format: "html", output: "out/report.html", want: `<svg id="fake"></svg>`},
+		{format: "md", output: "report.md", want: "![Threat model diagram](threatmodel/threatmodel.svg)"},
+		{format: "md", output: "out/report.md", want: "![Threat model diagram](../threatmodel/threatmodel.svg)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			dir := newProject(t, newLibrary(library.Threatmodel{Connections: []library.Connection{
+				{SourceComponent: "User:Browser", DestinationComponent: "WebApp:Web", Direction: "to", Details: "HTTPS"},
+			}}))
+			if err := os.Mkdir("out", 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := FileReport(Options{Formats: []string{tt.format}, Output: tt.output, Generated: time.Unix(0, 0).UTC()}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(tt.output)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(got), tt.want) {
+				t.Errorf("FileReport() wrote %s, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWritePages(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "site")
+	outside := filepath.Join(filepath.Dir(dir), "keep.md")
+	if err := os.WriteFile(outside, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writePages(dir, map[string]string{"index.md": "a", "components/web.md": "b", "components/db.md": "c", "threats/xss.md": "d"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "custom.md"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// A tampered manifest cannot remove files out of the directory.
+	manifest, err := os.ReadFile(filepath.Join(dir, pagesManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, pagesManifest), append(manifest, "../keep.md\n"...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writePages(dir, map[string]string{"index.md": "e", "components/web.md": "f"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "site/index.md", want: true},
+		{path: "site/components/web.md", want: true},
+		{path: "site/components/db.md"},
+		{path: "site/threats/xss.md"},
+		{path: "site/threats"},
+		{path: "site/custom.md", want: true},
+		{path: "keep.md", want: true},
+	}
+	for _, tt := range tests {
+		_, err := os.Stat(filepath.Join(filepath.Dir(dir), filepath.FromSlash(tt.path)))
+		if got := err == nil; got != tt.want {
+			t.Errorf("%s exists: %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// SARIF levels of the results, from the highest to the lowest severity.
+const (
+	sarifError   = "error"
+	sarifWarning = "warning"
+	sarifNote    = "note"
+)
+
+// sarifLevels maps the status of a threat against a component to the level of the results reporting it.
+var sarifLevels = map[string]string{
+	StatusExposed:     sarifError,
+	StatusAccepted:    sarifWarning,
+	StatusUntested:    sarifWarning,
+	StatusTransferred: sarifNote,
+	StatusMitigated:   sarifNote,
+}
+
+// Subset of the SARIF 2.1.0 object model used by the report.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool                     sarifTool                 `json:"tool"`
+		VersionControlProvenance []sarifVersionControlData `json:"versionControlProvenance,omitempty"`
+		Results                  []sarifResult             `json:"results"`
+	}
+	sarifVersionControlData struct {
+		RepositoryURI string `json:"repositoryUri"`
+		RevisionID    string `json:"revisionId,omitempty"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID                   string             `json:"id"`
+		Name                 string             `json:"name"`
+		ShortDescription     sarifMessage       `json:"shortDescription"`
+		FullDescription      *sarifMessage      `json:"fullDescription,omitempty"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+		Properties           sarifProperties    `json:"properties"`
+	}
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+	sarifProperties struct {
+		Tags []string `json:"tags,omitempty"`
+		// Threat, Component and Status qualify the results.
+		Threat    string `json:"threat,omitempty"`
+		Component string `json:"component,omitempty"`
+		Status    string `json:"status,omitempty"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID              string            `json:"ruleId"`
+		RuleIndex           int               `json:"ruleIndex"`
+		Level               string            `json:"level"`
+		Message             sarifMessage      `json:"message"`
+		Locations           []sarifLocation   `json:"locations,omitempty"`
+		PartialFingerprints map[string]string `json:"partialFingerprints"`
+		Properties          sarifProperties   `json:"properties"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	sarifArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId"`
+	}
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+)
+
+// sarifReport returns every exposure and every untested mitigation as a SARIF 2.1.0 log, with a rule per threat,
+// so that the gaps of the threat model show up in code scanning tools next to the results of static analysis.
+func sarifReport(v *View) (string, error) {
+	status := map[string]map[string]string{}
+	for _, cp := range v.Postures {
+		status[cp.Component] = map[string]string{}
+		for _, p := range cp.Threats {
+			status[cp.Component][p.Threat] = p.Status
+		}
+	}
+
+	var threats []string
+	for _, e := range v.ThreatModel.Exposures {
+		threats = append(threats, e.Threat)
+	}
+	for _, m := range v.Assurance.UntestedMitigations {
+		threats = append(threats, m.Threat)
+	}
+	ruleIDs := sarifRuleIDs(v.Library, threats)
+
+	var results []sarifResult
+	for _, e := range v.ThreatModel.Exposures {
+		s := status[e.Component][e.Threat]
+		results = append(results, sarifResult{
+			RuleID:    ruleIDs[e.Threat],
+			Level:     sarifLevels[s],
+			Message:   sarifMessage{Text: fmt.Sprintf("%s is exposed to %s with %s (%s).", e.Component, e.Threat, e.Details, s)},
+			Locations: sarifLocations(e.Source),
+			PartialFingerprints: map[string]string{
+				"threatModelEntry/v1": fmt.Sprintf("exposes %s to %s with %s", e.Component, e.Threat, e.Details),
+			},
+			Properties: sarifProperties{Threat: e.Threat, Component: e.Component, Status: s},
+		})
+	}
+	for _, m := range v.Assurance.UntestedMitigations {
+		results = append(results, sarifResult{
+			RuleID:    ruleIDs[m.Threat],
+			Level:     sarifWarning,
+			Message:   sarifMessage{Text: fmt.Sprintf("The mitigation of %s against %s with %s is not verified by a test.", m.Component, m.Threat, m.Control)},
+			Locations: sarifLocations(m.Source),
+			PartialFingerprints: map[string]string{
+				"threatModelEntry/v1": fmt.Sprintf("mitigates %s against %s with %s", m.Component, m.Threat, m.Control),
+			},
+			Properties: sarifProperties{Threat: m.Threat, Component: m.Component, Status: StatusUntested},
+		})
+	}
+
+	rules := sarifRules(v.Library, results)
+	index := map[string]int{}
+	for i, r := range rules {
+		index[r.ID] = i
+	}
+	for i := range results {
+		results[i].RuleIndex = index[results[i].RuleID]
+	}
+	if results == nil {
+		results = []sarifResult{}
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "famed-annotated",
+			InformationURI: "https://github.com/morphysm/famed-annotated",
+			Rules:          rules,
+		}},
+		Results: results,
+	}
+	if v.RepositoryURL != "" {
+		vcs := sarifVersionControlData{RepositoryURI: v.RepositoryURL}
+		if v.Commit != "HEAD" {
+			vcs.RevisionID = v.Commit
+		}
+		run.VersionControlProvenance = []sarifVersionControlData{vcs}
+	}
+
+	b, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}, "", " ")
+	if err != nil {
+		return "", eris.Wrap(err, "failed to marshal SARIF log")
+	}
+
+	return string(b) + "\n", nil
+}
+
+// sarifRuleIDs derives the id of the rule of each threat from the id of the threat in the library, or from the threat
+// itself when it is not in the library. Distinct threats whose ids have the same slug get rule ids suffixed with a
+// number, in the order of their ids.
+func sarifRuleIDs(l *library.Library, threats []string) map[string]string {
+	ids := map[string]string{}
+	for _, threat := range threats {
+		ids[threat] = threat
+		if t, ok := l.FindThreat(threat); ok && t.Id != "" {
+			ids[threat] = t.Id
+		}
+	}
+
+	rules := map[string]string{}
+	for _, id := range ids {
+		rules[id] = ""
+	}
+	used := map[string]bool{}
+	for _, id := range sortedNames(rules) {
+		base := "threat/" + slug(id)
+		rule := base
+		for i := 2; used[rule]; i++ {
+			rule = base + "-" + strconv.Itoa(i)
+		}
+		used[rule] = true
+		rules[id] = rule
+	}
+
+	for threat, id := range ids {
+		ids[threat] = rules[id]
+	}
+
+	return ids
+}
+
+// sarifRules returns the rules of the threats the results report, sorted by id, at the level of their most severe
+// result.
+func sarifRules(l *library.Library, results []sarifResult) []sarifRule {
+	ranks := map[string]int{sarifError: 0, sarifWarning: 1, sarifNote: 2}
+
+	byID := map[string]*sarifRule{}
+	for _, r := range results {
+		if rule, ok := byID[r.RuleID]; ok {
+			if ranks[r.Level] < ranks[rule.DefaultConfiguration.Level] {
+				rule.DefaultConfiguration.Level = r.Level
+			}
+			continue
+		}
+
+		name := r.Properties.Threat
+		rule := &sarifRule{
+			ID:                   r.RuleID,
+			Name:                 name,
+			ShortDescription:     sarifMessage{Text: name},
+			DefaultConfiguration: sarifConfiguration{Level: r.Level},
+			Properties:           sarifProperties{Tags: []string{"security", "threat-model"}},
+		}
+		if t, ok := l.FindThreat(name); ok && t.Description != "" {
+			rule.FullDescription = &sarifMessage{Text: t.Description}
+		}
+		byID[r.RuleID] = rule
+	}
+
+	rules := make([]sarifRule, 0, len(byID))
+	for _, rule := range byID {
+		rules = append(rules, *rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	return rules
+}
+
+// sarifLocations returns the location of the annotation, relative to the root of the sources. Entries without a
+// source file, like the ones of an imported model, have no location, and no region without a line, as SARIF requires
+// lines to start at 1.
+func sarifLocations(source library.Source) []sarifLocation {
+	if source.Filename == "" {
+		return nil
+	}
+
+	location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{
+			URI:       strings.TrimPrefix(filepath.ToSlash(filepath.Clean(source.Filename)), "./"),
+			URIBaseID: "%SRCROOT%",
+		},
+	}}
+	if source.Line >= 1 {
+		location.PhysicalLocation.Region = &sarifRegion{StartLine: source.Line}
+	}
+
+	return []sarifLocation{location}
+}
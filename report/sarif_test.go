@@ -0,0 +1,95 @@
+package report
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestSARIFReport(t *testing.T) {
+	l := newLibrary(library.Threatmodel{
+		Exposures: []library.Exposure{
+			{Threat: "XSS", Component: "WebApp:Web", Details: "unescaped names", Source: library.Source{Filename: "./web/handler.go", Line: 12}},
+			{Threat: "XSS", Component: "WebApp:Admin", Details: "unescaped names"},
+			{Threat: "SQL injection", Component: "WebApp:DB", Details: "raw queries", Source: library.Source{Filename: "db.go"}},
+			{Threat: "SQL-Injection", Component: "WebApp:Reports", Details: "raw queries"},
+			{Threat: "DoS", Component: "WebApp:API", Details: "no rate limit"},
+		},
+		Mitigations: []library.Mitigate{
+			{Threat: "XSS", Component: "WebApp:Admin", Control: "escaping"},
+			{Threat: "SQL-Injection", Component: "WebApp:Reports", Control: "prepared statements"},
+		},
+		Acceptances: []library.Acceptance{{Threat: "DoS", Component: "WebApp:API"}},
+		Tests:       []library.Test{{Control: "prepared statements", Component: "WebApp:Reports"}},
+	})
+	l.Threats["XSS"] = library.Threat{Id: "#xss", Name: "XSS", Description: "Cross-site scripting"}
+	v := newView(l)
+	v.Postures = postures(l)
+	v.Assurance = newAssurance(l.ThreatModel)
+
+	out, err := sarifReport(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got sarifLog
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatal(err)
+	}
+	run := got.Runs[0]
+
+	wantRules := []struct {
+		id, name, level string
+	}{
+		{id: "threat/dos", name: "DoS", level: sarifWarning},
+		{id: "threat/sql-injection", name: "SQL injection", level: sarifError},
+		{id: "threat/sql-injection-2", name: "SQL-Injection", level: sarifNote},
+		{id: "threat/xss", name: "XSS", level: sarifError},
+	}
+	if len(run.Tool.Driver.Rules) != len(wantRules) {
+		t.Fatalf("sarifReport() rules = %+v, want %d rules", run.Tool.Driver.Rules, len(wantRules))
+	}
+	for i, want := range wantRules {
+		rule := run.Tool.Driver.Rules[i]
+		if rule.ID != want.id || rule.Name != want.name || rule.DefaultConfiguration.Level != want.level {
+			t.Errorf("sarifReport() rule %d = %+v, want %+v", i, rule, want)
+		}
+	}
+	if rule := run.Tool.Driver.Rules[3]; rule.FullDescription == nil || rule.FullDescription.Text != "Cross-site scripting" {
+		t.Errorf("sarifReport() rule %+v, want the description of the threat", rule)
+	}
+
+	wantResults := []struct {
+		ruleID, level string
+		line          int
+	}{
+		{ruleID: "threat/xss", level: sarifError, line: 12},
+		{ruleID: "threat/xss", level: sarifWarning},
+		{ruleID: "threat/sql-injection", level: sarifError},
+		{ruleID: "threat/sql-injection-2", level: sarifNote},
+		{ruleID: "threat/dos", level: sarifWarning},
+		{ruleID: "threat/xss", level: sarifWarning},
+	}
+	if len(run.Results) != len(wantResults) {
+		t.Fatalf("sarifReport() results = %+v, want %d results", run.Results, len(wantResults))
+	}
+	for i, want := range wantResults {
+		r := run.Results[i]
+		if r.RuleID != want.ruleID || r.Level != want.level || run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID {
+			t.Errorf("sarifReport() result %d = %+v, want %+v", i, r, want)
+		}
+		line := 0
+		if len(r.Locations) > 0 && r.Locations[0].PhysicalLocation.Region != nil {
+			line = r.Locations[0].PhysicalLocation.Region.StartLine
+		}
+		if line != want.line {
+			t.Errorf("sarifReport() result %d line = %d, want %d", i, line, want.line)
+		}
+	}
+	if uri := run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "web/handler.go" {
+		t.Errorf("sarifReport() location = %q, want web/handler.go", uri)
+	}
+	if len(run.Results[2].Locations) != 1 || len(run.Results[1].Locations) != 0 {
+		t.Errorf("sarifReport() locations = %+v, %+v, want a file without region and none", run.Results[2].Locations, run.Results[1].Locations)
+	}
+}
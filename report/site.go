@@ -0,0 +1,367 @@
+package report
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// site names the pages of a documentation site: one page per component, threat and control, with cross-links.
+type site struct {
+	v          *View
+	components *section
+	threats    *section
+	controls   *section
+}
+
+// section names the pages of the entries of the library of a kind, in their own directory.
+type section struct {
+	dir string
+	// ids are the ids of the entries, sorted by name.
+	ids []string
+	// names and slugs are the names of the entries and the slugs of their pages, by id.
+	names map[string]string
+	slugs map[string]string
+	// refs resolve the references of the annotations, either ids or names, to the id of the entry.
+	refs map[string]string
+}
+
+// sitePages returns the threat model as a documentation site of Markdown pages with front matter, compatible with
+// MkDocs and Hugo: an index page, and a page per component, threat and control under their own directory.
+func sitePages(v *View) (map[string]string, error) {
+	s := &site{
+		v:          v,
+		components: newSection("components", v.Library.Components, func(c library.Component) string { return c.Name }),
+		threats:    newSection("threats", v.Threats, func(t library.Threat) string { return t.Name }),
+		controls:   newSection("controls", v.Library.Controls, func(c library.Control) string { return c.Name }),
+	}
+
+	// The source links of the pages are relative to their own directory.
+	root := v.outputDir
+	defer func() { v.outputDir = root }()
+
+	pages := map[string]string{"index.md": s.index()}
+	v.outputDir = filepath.Join(root, "components")
+	for id, c := range v.Library.Components {
+		pages["components/"+s.components.slugs[id]+".md"] = s.component(id, c)
+	}
+	v.outputDir = filepath.Join(root, "threats")
+	for id, t := range v.Threats {
+		pages["threats/"+s.threats.slugs[id]+".md"] = s.threat(id, t)
+	}
+	v.outputDir = filepath.Join(root, "controls")
+	for id, c := range v.Library.Controls {
+		pages["controls/"+s.controls.slugs[id]+".md"] = s.control(id, c)
+	}
+
+	return pages, nil
+}
+
+// newSection returns the section of the entries of the library, indexed by id. Each entry gets a unique slug of its
+// name, suffixed with a number on collision, and a name shared by several entries refers to the first one.
+func newSection[T any](dir string, entries map[string]T, name func(T) string) *section {
+	s := &section{dir: dir, names: map[string]string{}, slugs: map[string]string{}, refs: map[string]string{}}
+	for id, entry := range entries {
+		s.ids = append(s.ids, id)
+		s.names[id] = name(entry)
+		s.refs[id] = id
+	}
+	sort.Slice(s.ids, func(i, j int) bool {
+		a, b := s.ids[i], s.ids[j]
+		if s.names[a] != s.names[b] {
+			return s.names[a] < s.names[b]
+		}
+
+		return a < b
+	})
+
+	used := map[string]bool{}
+	for _, id := range s.ids {
+		base := slug(s.names[id])
+		slug := base
+		for i := 2; used[slug]; i++ {
+			slug = base + "-" + strconv.Itoa(i)
+		}
+		used[slug] = true
+		s.slugs[id] = slug
+
+		if _, ok := s.refs[s.names[id]]; !ok {
+			s.refs[s.names[id]] = id
+		}
+	}
+
+	return s
+}
+
+// is reports whether a reference of an annotation refers to the entry with the id.
+func (s *section) is(ref, id string) bool {
+	return s.refs[ref] == id
+}
+
+// slug lowercases the name and replaces every run of characters other than letters and digits with a dash.
+func slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	if s := strings.TrimSuffix(b.String(), "-"); s != "" {
+		return s
+	}
+
+	return "unnamed"
+}
+
+// frontMatter returns a page starting with its YAML front matter.
+func frontMatter(title string) *Markdown {
+	quoted, _ := json.Marshal(title)
+
+	md := NewMarkdown()
+	md.WriteWordLine("---")
+	md.WriteWordLine("title: " + string(quoted))
+	md.WriteWordLine("---")
+	md.Writeln()
+	md.WriteTitle(markdownEscape(title), 1)
+
+	return md
+}
+
+// link returns a link to the page of the entry a reference refers to from a page one directory deep, or the escaped
+// reference if it has no page.
+func (s *section) link(ref string) string {
+	id, ok := s.refs[ref]
+	if !ok {
+		return markdownEscape(ref)
+	}
+
+	return "[" + markdownEscape(s.names[id]) + "](../" + s.dir + "/" + s.slugs[id] + ".md)"
+}
+
+func (s *site) componentLink(ref string) string { return s.components.link(ref) }
+func (s *site) threatLink(ref string) string    { return s.threats.link(ref) }
+func (s *site) controlLink(ref string) string   { return s.controls.link(ref) }
+
+// index returns the index page: the executive summary, the diagram and the lists of pages.
+func (s *site) index() string {
+	md := frontMatter(s.v.Project.Name + " threat model")
+	md.Writeln()
+
+	if s.v.Project.Description != "" {
+		md.WriteWordLine(markdownEscape(s.v.Project.Description))
+		md.Writeln()
+	}
+	md.WriteWordLine(s.v.Summary.Narrative)
+	md.Writeln()
+
+	md.WriteTitle("Diagram", 2)
+	md.WriteMultiCode(s.v.Mermaid, "mermaid")
+	if s.v.Image != "" {
+		md.WriteWordLine("![Threat model diagram](" + s.v.Image + ")")
+	}
+	md.Writeln()
+
+	residual := map[string]int{}
+	for _, cp := range s.v.Postures {
+		if id, ok := s.components.refs[cp.Component]; ok {
+			residual[id] += cp.Residual
+		}
+	}
+
+	for _, section := range []struct {
+		title string
+		*section
+	}{
+		{title: "Components", section: s.components},
+		{title: "Threats", section: s.threats},
+		{title: "Controls", section: s.controls},
+	} {
+		if len(section.ids) == 0 {
+			continue
+		}
+
+		md.WriteTitle(section.title, 2)
+		md.Writeln()
+		for _, id := range section.ids {
+			md.Write("- ").WriteLink(markdownEscape(section.names[id]), section.dir+"/"+section.slugs[id]+".md")
+			if section.section == s.components && residual[id] > 0 {
+				md.Write(" (" + plural(residual[id], "residual risk") + ")")
+			}
+			md.Writeln()
+		}
+		md.Writeln()
+	}
+
+	return md.String()
+}
+
+// component returns the page of a component: its trust boundary, its risk posture and its flows.
+func (s *site) component(id string, c library.Component) string {
+	md := frontMatter(c.Name)
+	md.Writeln()
+
+	if len(c.Paths) > 0 && len(c.Paths[0]) > 0 {
+		md.WriteWordLine("Trust boundary: " + markdownEscape(strings.Join(c.Paths[0], ":")))
+		md.Writeln()
+	}
+	if c.Description != "" {
+		md.WriteWordLine(markdownEscape(c.Description))
+		md.Writeln()
+	}
+
+	for _, cp := range s.v.Postures {
+		if !s.components.is(cp.Component, id) {
+			continue
+		}
+
+		md.WriteTitle("Risk posture", 2)
+		md.Writeln()
+		t := newRecordTable(len(cp.Threats), "Threat", "Status", "Controls")
+		for row, p := range cp.Threats {
+			var controls []string
+			for _, control := range p.Controls {
+				controls = append(controls, s.controlLink(control))
+			}
+			t.SetRawContent(row, 0, s.threatLink(p.Threat)).
+				SetContent(row, 1, p.Status).
+				SetRawContent(row, 2, strings.Join(controls, ", "))
+		}
+		md.WriteTable(t)
+		md.Writeln()
+	}
+
+	var flows []string
+	for _, conn := range s.v.ThreatModel.Connections {
+		if s.components.is(conn.SourceComponent, id) || s.components.is(conn.DestinationComponent, id) {
+			flows = append(flows, s.componentLink(conn.SourceComponent)+" "+conn.Direction+" "+
+				s.componentLink(conn.DestinationComponent)+": "+markdownEscape(conn.Details)+" "+s.v.sourceLink(conn.Source))
+		}
+	}
+	for _, tr := range s.v.ThreatModel.Transfers {
+		if s.components.is(tr.SourceComponent, id) || s.components.is(tr.DestinationComponent, id) {
+			flows = append(flows, s.threatLink(tr.Threat)+" transferred from "+s.componentLink(tr.SourceComponent)+" to "+
+				s.componentLink(tr.DestinationComponent)+": "+markdownEscape(tr.Details)+" "+s.v.sourceLink(tr.Source))
+		}
+	}
+	if len(flows) > 0 {
+		md.WriteTitle("Flows", 2)
+		md.Writeln()
+		for _, flow := range flows {
+			md.WriteWordLine("- " + flow)
+		}
+		md.Writeln()
+	}
+
+	var reviews []string
+	for _, r := range s.v.ThreatModel.Reviews {
+		if s.components.is(r.Component, id) {
+			reviews = append(reviews, markdownEscape(r.Details)+" "+s.v.sourceLink(r.Source))
+		}
+	}
+	if len(reviews) > 0 {
+		md.WriteTitle("Reviews", 2)
+		md.Writeln()
+		for _, review := range reviews {
+			md.WriteWordLine("- " + review)
+		}
+		md.Writeln()
+	}
+
+	return md.String()
+}
+
+// threat returns the page of a threat: the status of the threat against every component it touches.
+func (s *site) threat(id string, t library.Threat) string {
+	md := frontMatter(t.Name)
+	md.Writeln()
+
+	if t.Description != "" {
+		md.WriteWordLine(markdownEscape(t.Description))
+		md.Writeln()
+	}
+
+	type row struct {
+		component string
+		p         ThreatPosture
+	}
+	var rows []row
+	for _, cp := range s.v.Postures {
+		for _, p := range cp.Threats {
+			if s.threats.is(p.Threat, id) {
+				rows = append(rows, row{component: cp.Component, p: p})
+			}
+		}
+	}
+
+	if len(rows) > 0 {
+		md.WriteTitle("Components", 2)
+		md.Writeln()
+		table := newRecordTable(len(rows), "Component", "Status", "Controls")
+		for i, r := range rows {
+			var controls []string
+			for _, control := range r.p.Controls {
+				controls = append(controls, s.controlLink(control))
+			}
+			table.SetRawContent(i, 0, s.componentLink(r.component)).
+				SetContent(i, 1, r.p.Status).
+				SetRawContent(i, 2, strings.Join(controls, ", "))
+		}
+		md.WriteTable(table)
+		md.Writeln()
+	}
+
+	return md.String()
+}
+
+// control returns the page of a control: the mitigations it implements and their tests.
+func (s *site) control(id string, c library.Control) string {
+	md := frontMatter(c.Name)
+	md.Writeln()
+
+	if c.Description != "" {
+		md.WriteWordLine(markdownEscape(c.Description))
+		md.Writeln()
+	}
+
+	var mitigations []library.Mitigate
+	for _, m := range s.v.ThreatModel.Mitigations {
+		if s.controls.is(m.Control, id) {
+			mitigations = append(mitigations, m)
+		}
+	}
+
+	if len(mitigations) > 0 {
+		md.WriteTitle("Mitigations", 2)
+		md.Writeln()
+		t := newRecordTable(len(mitigations), "Threat", "Component", "Source", "Tests")
+		for row, m := range mitigations {
+			var links []string
+			for _, test := range testsFor(m, s.v.ThreatModel.Tests) {
+				links = append(links, s.v.sourceLink(test.Source))
+			}
+			if len(links) == 0 {
+				links = append(links, "none")
+			}
+			t.SetRawContent(row, 0, s.threatLink(m.Threat)).
+				SetRawContent(row, 1, s.componentLink(m.Component)).
+				SetRawContent(row, 2, s.v.sourceLink(m.Source)).
+				SetRawContent(row, 3, strings.Join(links, "<br>"))
+		}
+		md.WriteTable(t)
+		md.Writeln()
+	}
+
+	return md.String()
+}
@@ -0,0 +1,95 @@
+package report
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "WebApp:Web", want: "webapp-web"},
+		{name: "SQL injection (#sqli)", want: "sql-injection-sqli"},
+		{name: "  trailing!  ", want: "trailing"},
+		{name: "日本", want: "unnamed"},
+	}
+
+	for _, tt := range tests {
+		if got := slug(tt.name); got != tt.want {
+			t.Errorf("slug(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSitePages(t *testing.T) {
+	l := newLibrary(library.Threatmodel{
+		Exposures: []library.Exposure{
+			{Threat: "XSS", Component: "#web-2"},
+			{Threat: "SQLi", Component: "Web"},
+		},
+		Mitigations: []library.Mitigate{{Threat: "XSS", Component: "#web-2", Control: "escaping"}},
+	})
+	delete(l.Components, "Web")
+	l.Components["#web-1"] = library.Component{Id: "#web-1", Name: "Web", Description: "first"}
+	l.Components["#web-2"] = library.Component{Id: "#web-2", Name: "Web", Description: "second"}
+	l.Components["#web-app"] = library.Component{Id: "#web-app", Name: "Web app"}
+	l.Components["#web_app"] = library.Component{Id: "#web_app", Name: "web-app"}
+
+	pages, err := sitePages(newView(l))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for name := range pages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	want := []string{
+		"components/web-2.md",
+		"components/web-app-2.md",
+		"components/web-app.md",
+		"components/web.md",
+		"controls/escaping.md",
+		"index.md",
+		"threats/sqli.md",
+		"threats/xss.md",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("sitePages() = %v, want %v", names, want)
+	}
+
+	tests := []struct {
+		page    string
+		want    []string
+		notWant []string
+	}{
+		{page: "components/web.md", want: []string{"first", "|[SQLi](../threats/sqli.md)|exposed and unmitigated|"}, notWant: []string{"XSS"}},
+		{page: "components/web-2.md", want: []string{"second", "|[XSS](../threats/xss.md)|mitigated but untested|[escaping](../controls/escaping.md)|"}, notWant: []string{"SQLi"}},
+		{page: "threats/xss.md", want: []string{"|[Web](../components/web-2.md)|"}},
+		{page: "threats/sqli.md", want: []string{"|[Web](../components/web.md)|"}},
+		{page: "controls/escaping.md", want: []string{"|[XSS](../threats/xss.md)|[Web](../components/web-2.md)|"}},
+		{page: "index.md", want: []string{"- [Web](components/web.md) (1 residual risk)\n- [Web](components/web-2.md)\n- [Web app](components/web-app.md)\n- [web-app](components/web-app-2.md)\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.page, func(t *testing.T) {
+			for _, s := range tt.want {
+				if !strings.Contains(pages[tt.page], s) {
+					t.Errorf("sitePages() %s = %s\nwant %q", tt.page, pages[tt.page], s)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(pages[tt.page], s) {
+					t.Errorf("sitePages() %s = %s\nwant no %q", tt.page, pages[tt.page], s)
+				}
+			}
+		})
+	}
+}
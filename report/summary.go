@@ -0,0 +1,129 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// topRiskyComponents is the number of components listed as the riskiest in the executive summary.
+const topRiskyComponents = 3
+
+// Summary holds the headline metrics of the executive summary.
+type Summary struct {
+	Scope                library.Scope `json:"scope"`
+	Components           int           `json:"components"`
+	UnmitigatedExposures int           `json:"unmitigated_exposures"`
+	Acceptances          int           `json:"acceptances"`
+	// UntestedControls are the controls of the mitigations no test verifies, sorted.
+	UntestedControls []string `json:"untested_controls"`
+	// TopRisky are the components with the most residual risks, at most three of them.
+	TopRisky []ComponentPosture `json:"top_risky"`
+	// Narrative sums up the metrics in a few sentences.
+	Narrative string `json:"narrative"`
+}
+
+// newSummary computes the headline metrics of the view and writes their narrative.
+func newSummary(v *View) Summary {
+	s := Summary{
+		Scope:       v.ThreatModel.Scope,
+		Components:  v.Statistics.Components,
+		Acceptances: v.Statistics.Acceptances,
+	}
+
+	for _, cp := range v.Postures {
+		for _, p := range cp.Threats {
+			if p.Status == StatusExposed {
+				s.UnmitigatedExposures++
+			}
+		}
+		if cp.Residual > 0 && len(s.TopRisky) < topRiskyComponents {
+			s.TopRisky = append(s.TopRisky, cp)
+		}
+	}
+
+	controls := map[string]bool{}
+	for _, m := range v.Assurance.UntestedMitigations {
+		controls[m.Control] = true
+	}
+	s.UntestedControls = keys(controls)
+
+	s.Narrative = narrative(v, s)
+
+	return s
+}
+
+// Languages returns the languages of the scope, sorted.
+func (s Summary) Languages() []string {
+	languages := make([]string, 0, len(s.Scope.Languages))
+	for language := range s.Scope.Languages {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	return languages
+}
+
+// narrative sums up the headline metrics in a few sentences.
+func narrative(v *View, s Summary) string {
+	var sentences []string
+
+	scope := fmt.Sprintf("The threat model of %s covers %s", v.Project.Name, plural(s.Components, "component"))
+	if s.Scope.Files > 0 {
+		scope += fmt.Sprintf(", annotated across %s", plural(s.Scope.Files, "file"))
+		if languages := s.Languages(); len(languages) > 0 {
+			scope += " of " + strings.Join(languages, ", ")
+		}
+	}
+	sentences = append(sentences, scope+".")
+
+	switch s.UnmitigatedExposures {
+	case 0:
+		sentences = append(sentences, "No component is exposed to an unmitigated threat.")
+	default:
+		sentences = append(sentences, fmt.Sprintf("%s exposed without mitigation.", pluralVerb(s.UnmitigatedExposures, "threat is", "threats are")))
+	}
+
+	if s.Acceptances > 0 {
+		sentences = append(sentences, fmt.Sprintf("%s accepted.", pluralVerb(s.Acceptances, "risk was", "risks were")))
+	}
+
+	if total := v.Assurance.Tested + v.Assurance.Untested; total > 0 {
+		sentences = append(sentences, fmt.Sprintf("%d of %s (%d%%) are verified by a test.",
+			v.Assurance.Tested, plural(total, "mitigation"), v.Assurance.Percent))
+	}
+
+	if len(s.TopRisky) > 0 {
+		var names []string
+		for _, cp := range s.TopRisky {
+			names = append(names, fmt.Sprintf("%s (%s)", cp.Component, plural(cp.Residual, "residual risk")))
+		}
+		if len(names) == 1 {
+			sentences = append(sentences, "The riskiest component is "+names[0]+".")
+		} else {
+			sentences = append(sentences, "The riskiest components are "+strings.Join(names, ", ")+".")
+		}
+	}
+
+	return strings.Join(sentences, " ")
+}
+
+// plural returns the count followed by the noun, in the plural if the count is not one.
+func plural(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// pluralVerb returns the count followed by the singular or the plural phrase.
+func pluralVerb(count int, singular, plurals string) string {
+	if count == 1 {
+		return "1 " + singular
+	}
+
+	return fmt.Sprintf("%d %s", count, plurals)
+}
@@ -0,0 +1,125 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// summaryView returns the view of the threat model with its statistics and summary, as NewView computes them.
+func summaryView(tm library.Threatmodel) *View {
+	v := newView(newLibrary(tm))
+	v.Statistics = Statistics{Components: len(v.Library.Components), Acceptances: len(tm.Acceptances)}
+	v.Summary = newSummary(v)
+
+	return v
+}
+
+func TestNewSummary(t *testing.T) {
+	tests := []struct {
+		name             string
+		tm               library.Threatmodel
+		wantExposures    int
+		wantUntested     []string
+		wantTopRisky     []string
+		wantNarrative    []string
+		notWantNarrative []string
+	}{
+		{
+			name:             "empty",
+			wantNarrative:    []string{"The threat model of shop covers 0 components.", "No component is exposed to an unmitigated threat."},
+			notWantNarrative: []string{"verified by a test", "riskiest"},
+		},
+		{
+			name: "risks",
+			tm: library.Threatmodel{
+				Exposures: []library.Exposure{
+					{Threat: "XSS", Component: "WebApp:Web"},
+					{Threat: "SQLi", Component: "WebApp:DB"},
+					{Threat: "CSRF", Component: "WebApp:Web"},
+				},
+				Mitigations: []library.Mitigate{
+					{Threat: "CSRF", Component: "WebApp:Web", Control: "tokens"},
+					{Threat: "SQLi", Component: "WebApp:DB", Control: "prepared statements"},
+				},
+				Acceptances: []library.Acceptance{{Threat: "DoS", Component: "WebApp:API"}},
+				Tests:       []library.Test{{Control: "tokens", Component: "WebApp:Web"}},
+				Scope:       library.Scope{Files: 3, Languages: map[string]int{"Go": 2, "Python": 1}},
+			},
+			wantExposures: 1,
+			wantUntested:  []string{"prepared statements"},
+			wantTopRisky:  []string{"WebApp:API", "WebApp:Web"},
+			wantNarrative: []string{
+				"covers 3 components, annotated across 3 files of Go, Python.",
+				"1 threat is exposed without mitigation.",
+				"1 risk was accepted.",
+				"1 of 2 mitigations (50%) are verified by a test.",
+				"The riskiest components are WebApp:API (1 residual risk), WebApp:Web (1 residual risk).",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := summaryView(tt.tm).Summary
+
+			var topRisky []string
+			for _, cp := range s.TopRisky {
+				topRisky = append(topRisky, cp.Component)
+			}
+			if s.UnmitigatedExposures != tt.wantExposures || strings.Join(s.UntestedControls, ",") != strings.Join(tt.wantUntested, ",") ||
+				strings.Join(topRisky, ",") != strings.Join(tt.wantTopRisky, ",") {
+				t.Errorf("newSummary() = %+v, want %d unmitigated exposures, untested %v and top risky %v", s, tt.wantExposures, tt.wantUntested, tt.wantTopRisky)
+			}
+			for _, sentence := range tt.wantNarrative {
+				if !strings.Contains(s.Narrative, sentence) {
+					t.Errorf("newSummary().Narrative = %q, want %q", s.Narrative, sentence)
+				}
+			}
+			for _, sentence := range tt.notWantNarrative {
+				if strings.Contains(s.Narrative, sentence) {
+					t.Errorf("newSummary().Narrative = %q, want no %q", s.Narrative, sentence)
+				}
+			}
+		})
+	}
+}
+
+func TestReportSections(t *testing.T) {
+	tests := []struct {
+		name  string
+		image string
+		trend Trend
+	}{
+		{name: "diagram only"},
+		{name: "image", image: "threatmodel/threatmodel.svg"},
+		{name: "trend", trend: Trend{Points: []TrendPoint{{Taken: time.Unix(1, 0).UTC(), Exposures: 1}}}},
+		{name: "image and trend", image: "threatmodel/threatmodel.svg", trend: Trend{Points: []TrendPoint{{Taken: time.Unix(1, 0).UTC()}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := summaryView(library.Threatmodel{Exposures: []library.Exposure{{Threat: "XSS", Component: "WebApp:Web"}}})
+			v.Image, v.Trend = tt.image, tt.trend
+
+			got, err := report(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(got, "# shop threat model report\n") || !strings.Contains(got, "## Executive summary\n\nThe threat model of shop") {
+				t.Errorf("report() = %s\nwant the executive summary first", got)
+			}
+			lines := strings.Split(got, "\n")
+			for i, line := range lines {
+				if i > 0 && strings.HasPrefix(line, "## ") && lines[i-1] != "" {
+					t.Errorf("report() section %q follows %q, want a blank line", line, lines[i-1])
+				}
+			}
+			if tt.image != "" && !strings.Contains(got, "\n\n![Threat model diagram]("+tt.image+")\n\n") {
+				t.Errorf("report() = %s\nwant the image in its own paragraph", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Layout of the built-in diagram: each trust boundary is a column of components.
+const (
+	svgColumn = 260
+	svgRow    = 110
+	svgMargin = 40
+	svgWidth  = 180
+	svgHeight = 50
+	svgLabel  = 24
+)
+
+// svgDiagram returns the data-flow diagram as an SVG image, drawn without Graphviz so that the HTML report shows a
+// diagram offline: components are laid out in a column per trust boundary, components exposed to a threat are
+// outlined in red, and transfers are dashed.
+func svgDiagram(d *diagram) string {
+	columns := map[string][]*node{}
+	for _, n := range d.nodes {
+		columns[n.cluster.path] = append(columns[n.cluster.path], n)
+	}
+	boundaries := make([]string, 0, len(columns))
+	for boundary := range columns {
+		boundaries = append(boundaries, boundary)
+	}
+	sort.Strings(boundaries)
+
+	type point struct{ x, y float64 }
+	centers := map[*node]point{}
+	rows := 0
+	for i, boundary := range boundaries {
+		for row, n := range columns[boundary] {
+			centers[n] = point{
+				x: float64(svgMargin + i*svgColumn + svgColumn/2),
+				y: float64(2*svgMargin + row*svgRow + svgHeight/2),
+			}
+		}
+		if len(columns[boundary]) > rows {
+			rows = len(columns[boundary])
+		}
+	}
+
+	width, height := 2*svgMargin+len(boundaries)*svgColumn, 3*svgMargin+rows*svgRow
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", width, height, width, height)
+	b.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse"><path d="M0,0 L10,5 L0,10 z" fill="#57606a"/></marker></defs>` + "\n")
+
+	for i, boundary := range boundaries {
+		if boundary == "" {
+			continue
+		}
+		x := svgMargin + i*svgColumn + svgMargin/4
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="#cf222e" stroke-dasharray="6 4" rx="8"/>`+"\n",
+			x, svgMargin, svgColumn-svgMargin/2, len(columns[boundary])*svgRow+svgMargin/2)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#cf222e">%s</text>`+"\n", x+8, svgMargin+16, svgText(boundary))
+	}
+
+	// Edges between the same components are curved apart, and edges within a column bend around it.
+	pairs := map[[2]*node]int{}
+	for _, e := range d.edges {
+		from, to := centers[e.from], centers[e.to]
+		if from == to {
+			continue
+		}
+		pair := [2]*node{e.from, e.to}
+		if from.x > to.x || (from.x == to.x && from.y > to.y) {
+			pair = [2]*node{e.to, e.from}
+		}
+		k := pairs[pair]
+		pairs[pair]++
+
+		var x1, y1, x2, y2, cx, cy float64
+		if from.x == to.x {
+			x1, y1, x2, y2 = from.x+svgWidth/2, from.y, to.x+svgWidth/2, to.y
+			cx, cy = x1+float64(60+30*k), (y1+y2)/2
+		} else {
+			x1, y1 = svgBorder(from.x, from.y, to.x, to.y)
+			x2, y2 = svgBorder(to.x, to.y, from.x, from.y)
+			offset := float64(50 * k)
+			if pair[0] != e.from {
+				offset = -offset
+			}
+			length := math.Hypot(x2-x1, y2-y1)
+			cx, cy = (x1+x2)/2-(y2-y1)/length*offset, (y1+y2)/2+(x2-x1)/length*offset
+		}
+
+		attributes := `marker-end="url(#arrow)"`
+		if e.bidirectional {
+			attributes += ` marker-start="url(#arrow)"`
+		}
+		if e.transfer {
+			attributes += ` stroke-dasharray="4 3"`
+		}
+		fmt.Fprintf(&b, `<path d="M%.0f,%.0f Q%.0f,%.0f %.0f,%.0f" fill="none" stroke="#57606a" %s/>`+"\n",
+			x1, y1, cx, cy, x2, y2, attributes)
+		if e.label != "" {
+			fmt.Fprintf(&b, `<text x="%.0f" y="%.0f" text-anchor="middle" fill="#57606a">%s</text>`+"\n",
+				(x1+2*cx+x2)/4, (y1+2*cy+y2)/4-4, svgText(e.label))
+		}
+	}
+
+	for _, n := range d.nodes {
+		c := centers[n]
+		stroke := "#24292f"
+		if len(n.exposures) > 0 {
+			stroke = "#cf222e"
+		}
+		fmt.Fprintf(&b, `<g><title>%s</title><rect x="%.0f" y="%.0f" width="%d" height="%d" fill="#f6f8fa" stroke="%s" rx="6"/>`,
+			html.EscapeString(n.name), c.x-svgWidth/2, c.y-svgHeight/2, svgWidth, svgHeight, stroke)
+		fmt.Fprintf(&b, `<text x="%.0f" y="%.0f" text-anchor="middle" dominant-baseline="middle">%s</text></g>`+"\n",
+			c.x, c.y, svgText(n.name))
+	}
+
+	b.WriteString("</svg>")
+
+	return b.String()
+}
+
+// svgBorder returns where the line from the center of a component to another point leaves the box of the component.
+func svgBorder(x, y, toX, toY float64) (float64, float64) {
+	dx, dy := toX-x, toY-y
+	scale := math.Min(svgWidth/2/math.Abs(dx), svgHeight/2/math.Abs(dy))
+
+	return x + dx*scale, y + dy*scale
+}
+
+// svgText escapes a label, shortened to fit in a component box.
+func svgText(text string) string {
+	if runes := []rune(text); len(runes) > svgLabel {
+		text = string(runes[:svgLabel-1]) + "…"
+	}
+
+	return html.EscapeString(text)
+}
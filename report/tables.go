@@ -0,0 +1,132 @@
+package report
+
+import (
+	"strings"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// newRecordTable returns a table with the given column titles and one row per record.
+func newRecordTable(rows int, titles ...string) *Table {
+	t := NewTable(rows, len(titles))
+	for col, title := range titles {
+		t.SetTitle(col, title)
+	}
+
+	return t
+}
+
+// testsFor returns the tests of the control of a mitigation for its component.
+func testsFor(m library.Mitigate, tests []library.Test) []library.Test {
+	var found []library.Test
+	for _, t := range tests {
+		if t.Control == m.Control && t.Component == m.Component {
+			found = append(found, t)
+		}
+	}
+
+	return found
+}
+
+// mitigationsTable returns the mitigations as a Markdown table, with the tests of their control.
+func (v *View) mitigationsTable(mitigations []library.Mitigate, tests []library.Test) string {
+	t := newRecordTable(len(mitigations), "Threat", "Component", "Control", "Source", "Tests")
+	for row, m := range mitigations {
+		var links []string
+		for _, test := range testsFor(m, tests) {
+			links = append(links, v.sourceLink(test.Source))
+		}
+		if len(links) == 0 {
+			links = append(links, "none")
+		}
+
+		t.SetContent(row, 0, m.Threat).
+			SetContent(row, 1, m.Component).
+			SetContent(row, 2, m.Control).
+			SetRawContent(row, 3, v.sourceLink(m.Source)).
+			SetRawContent(row, 4, strings.Join(links, "<br>"))
+	}
+
+	return t.String()
+}
+
+// exposuresTable returns the exposures as a Markdown table.
+func (v *View) exposuresTable(exposures []library.Exposure) string {
+	t := newRecordTable(len(exposures), "Component", "Threat", "Details", "Source")
+	for row, e := range exposures {
+		t.SetContent(row, 0, e.Component).
+			SetContent(row, 1, e.Threat).
+			SetContent(row, 2, e.Details).
+			SetRawContent(row, 3, v.sourceLink(e.Source))
+	}
+
+	return t.String()
+}
+
+// acceptancesTable returns the acceptances as a Markdown table.
+func (v *View) acceptancesTable(acceptances []library.Acceptance) string {
+	t := newRecordTable(len(acceptances), "Threat", "Component", "Details", "Source")
+	for row, a := range acceptances {
+		t.SetContent(row, 0, a.Threat).
+			SetContent(row, 1, a.Component).
+			SetContent(row, 2, a.Details).
+			SetRawContent(row, 3, v.sourceLink(a.Source))
+	}
+
+	return t.String()
+}
+
+// transfersTable returns the transfers as a Markdown table.
+func (v *View) transfersTable(transfers []library.Transfer) string {
+	t := newRecordTable(len(transfers), "Threat", "From", "To", "Details", "Source")
+	for row, tr := range transfers {
+		t.SetContent(row, 0, tr.Threat).
+			SetContent(row, 1, tr.SourceComponent).
+			SetContent(row, 2, tr.DestinationComponent).
+			SetContent(row, 3, tr.Details).
+			SetRawContent(row, 4, v.sourceLink(tr.Source))
+	}
+
+	return t.String()
+}
+
+// connectionsTable returns the connections as a Markdown table.
+func (v *View) connectionsTable(connections []library.Connection) string {
+	t := newRecordTable(len(connections), "From", "Direction", "To", "Details", "Source")
+	t.SetAlignment(1, AlignCenter)
+	for row, c := range connections {
+		t.SetContent(row, 0, c.SourceComponent).
+			SetContent(row, 1, c.Direction).
+			SetContent(row, 2, c.DestinationComponent).
+			SetContent(row, 3, c.Details).
+			SetRawContent(row, 4, v.sourceLink(c.Source))
+	}
+
+	return t.String()
+}
+
+// reviewsTable returns the reviews as a Markdown table.
+func (v *View) reviewsTable(reviews []library.Review) string {
+	t := newRecordTable(len(reviews), "Component", "Details", "Source")
+	for row, r := range reviews {
+		t.SetContent(row, 0, r.Component).
+			SetContent(row, 1, r.Details).
+			SetRawContent(row, 2, v.sourceLink(r.Source))
+	}
+
+	return t.String()
+}
+
+// crossingsTable returns the flows crossing a trust boundary as a Markdown table.
+func crossingsTable(crossings []Crossing) string {
+	t := newRecordTable(len(crossings), "From", "To", "From boundary", "To boundary", "Details")
+	for row, c := range crossings {
+		t.SetContent(row, 0, c.From).
+			SetContent(row, 1, c.To).
+			SetContent(row, 2, c.FromBoundary).
+			SetContent(row, 3, c.ToBoundary).
+			SetContent(row, 4, c.Details)
+	}
+
+	return t.String()
+}
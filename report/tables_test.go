@@ -0,0 +1,63 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestTables(t *testing.T) {
+	v := newView(newLibrary(library.Threatmodel{}))
+
+	tests := []struct {
+		name  string
+		table func() string
+		want  string
+	}{
+		{
+			name: "mitigations with tests",
+			table: func() string {
+				return v.mitigationsTable(
+					[]library.Mitigate{
+						{Threat: "XSS", Component: "WebApp:Web", Control: "escaping"},
+						{Threat: "SQLi", Component: "WebApp:DB", Control: "prepared statements"},
+					},
+					[]library.Test{
+						{Control: "escaping", Component: "WebApp:Web", Source: library.Source{Filename: "web_test.go", Line: 3}},
+						{Control: "escaping", Component: "WebApp:Other", Source: library.Source{Filename: "other_test.go", Line: 5}},
+					},
+				)
+			},
+			want: "|Threat|Component|Control|Source|Tests|\n" +
+				"|----|----|----|----|----|\n" +
+				"|XSS|WebApp:Web|escaping||[web\\_test.go:3](web_test.go#L3)|\n" +
+				"|SQLi|WebApp:DB|prepared statements||none|\n",
+		},
+		{
+			name: "exposure details",
+			table: func() string {
+				return v.exposuresTable([]library.Exposure{{Component: "WebApp:Web", Threat: "XSS", Details: "a|b\nc"}})
+			},
+			want: "|Component|Threat|Details|Source|\n" +
+				"|----|----|----|----|\n" +
+				"|WebApp:Web|XSS|a\\|b<br>c||\n",
+		},
+		{
+			name: "centered connection direction",
+			table: func() string {
+				return v.connectionsTable([]library.Connection{{SourceComponent: "User:Browser", Direction: "to", DestinationComponent: "WebApp:Web", Details: "HTTPS"}})
+			},
+			want: "|From|Direction|To|Details|Source|\n" +
+				"|----|:---:|----|----|----|\n" +
+				"|User:Browser|to|WebApp:Web|HTTPS||\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.table(); got != tt.want {
+				t.Errorf("table = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
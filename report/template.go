@@ -0,0 +1,72 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+//go:embed templates/report.md.tmpl
+var markdownTemplate string
+
+// markdownEscaper escapes the characters that Markdown would interpret as formatting.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `#`, `\#`, `|`, `\|`, "\r\n", " ", "\n", " ",
+)
+
+// funcs returns the helper functions available to the report templates, in addition to the text/template builtins.
+func (v *View) funcs() template.FuncMap {
+	return template.FuncMap{
+		"markdownEscape": markdownEscape,
+		"sourceLink":     v.sourceLink,
+		"join":           strings.Join,
+
+		"mitigationsTable":    v.mitigationsTable,
+		"exposuresTable":      v.exposuresTable,
+		"acceptancesTable":    v.acceptancesTable,
+		"transfersTable":      v.transfersTable,
+		"connectionsTable":    v.connectionsTable,
+		"reviewsTable":        v.reviewsTable,
+		"crossingsTable":      crossingsTable,
+		"attackPatternsTable": attackPatternsTable,
+		"postureTable":        v.postureTable,
+		"untestedTable":       v.untestedTable,
+		"testsTable":          v.testsTable,
+		"trendTable":          trendTable,
+	}
+}
+
+// markdownEscape escapes text so that it is rendered literally in Markdown, on a single line.
+func markdownEscape(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// sourceLink returns a Markdown link to the file and line of an annotation, or an empty string if its source is unknown.
+func (v *View) sourceLink(source library.Source) string {
+	if source.Filename == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("[%s:%d](%s)", markdownEscape(source.Filename), source.Line, v.SourceURL(source))
+}
+
+// executeTemplate executes a report template against the view.
+func executeTemplate(name, text string, v *View) (string, error) {
+	t, err := template.New(name).Funcs(v.funcs()).Parse(text)
+	if err != nil {
+		return "", eris.Wrapf(err, "failed to parse template %s", name)
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, v); err != nil {
+		return "", eris.Wrapf(err, "failed to execute template %s", name)
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,100 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestMarkdownEscape(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "plain text", want: "plain text"},
+		{text: "a|b", want: `a\|b`},
+		{text: "*bold* _it_ `code`", want: "\\*bold\\* \\_it\\_ \\`code\\`"},
+		{text: "[link](<url>) #1", want: `\[link\](\<url\>) \#1`},
+		{text: `C:\dir`, want: `C:\\dir`},
+		{text: "two\nlines\r\nthree", want: "two lines three"},
+	}
+
+	for _, tt := range tests {
+		if got := markdownEscape(tt.text); got != tt.want {
+			t.Errorf("markdownEscape(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteTemplate(t *testing.T) {
+	v := newView(newLibrary(library.Threatmodel{
+		Exposures: []library.Exposure{{Threat: "XSS", Component: "WebApp:Web", Details: "a|b"}},
+	}))
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{name: "fields", text: "{{.Project.Name}}", want: "shop"},
+		{name: "escape", text: `{{range .ThreatModel.Exposures}}{{markdownEscape .Details}}{{end}}`, want: `a\|b`},
+		{name: "unknown source", text: `{{range .ThreatModel.Exposures}}[{{sourceLink .Source}}]{{end}}`, want: "[]"},
+		{name: "parse error", text: "{{.Project.Name", wantErr: true},
+		{name: "execution error", text: "{{.Missing}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := executeTemplate("custom.md.tmpl", tt.text, v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("executeTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("executeTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileReportTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		output   string
+		want     string
+		wantErr  bool
+	}{
+		{name: "named after the template", template: "custom.md.tmpl", want: "custom.md"},
+		{name: "output", template: "custom.md.tmpl", output: "out.md", want: "out.md"},
+		{name: "without extension", template: "custom.md", wantErr: true},
+		{name: "output over the template", template: "custom.md.tmpl", output: "custom.md.tmpl", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newProject(t, newLibrary(library.Threatmodel{}))
+			if err := os.WriteFile(filepath.Join(dir, tt.template), []byte("# {{.Project.Name}}\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			err := FileReport(Options{Formats: []string{"md"}, Output: tt.output, Templates: []string{tt.template}, Generated: time.Unix(0, 0).UTC()})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FileReport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if text, _ := os.ReadFile(filepath.Join(dir, tt.template)); string(text) != "# {{.Project.Name}}\n" {
+					t.Errorf("FileReport() overwrote the template with %q", text)
+				}
+
+				return
+			}
+			if _, err := os.Stat(filepath.Join(dir, tt.want)); err != nil {
+				t.Errorf("FileReport() did not write %s: %v", tt.want, err)
+			}
+		})
+	}
+}
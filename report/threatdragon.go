@@ -0,0 +1,367 @@
+package report
+
+import (
+	"crypto/sha1" //nolint:gosec // only derives stable identifiers
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// threatDragonVersion is the version of the OWASP Threat Dragon model format that is exported.
+const threatDragonVersion = "2.2.0"
+
+// Layout of the diagram: each trust boundary is a column of elements.
+const (
+	tdColumn = 250
+	tdRow    = 150
+	tdMargin = 50
+)
+
+// Keywords of the component names exported as actors and as data stores, other components being processes.
+var (
+	tdActorKeywords = tdKeywords("user", "users", "browser", "client", "actor", "admin", "attacker", "customer", "operator")
+	tdStoreKeywords = tdKeywords("db", "database", "store", "storage", "filesystem", "file", "files", "cache", "bucket", "queue", "log", "logs", "disk")
+)
+
+// tdKeywords returns a set of keywords.
+func tdKeywords(keywords ...string) map[string]bool {
+	set := make(map[string]bool, len(keywords))
+	for _, keyword := range keywords {
+		set[keyword] = true
+	}
+
+	return set
+}
+
+// tdThreats maps the status of a threat against a component to the status and severity of the Threat Dragon threat.
+var tdThreats = map[string]struct{ status, severity string }{
+	StatusExposed:     {status: "Open", severity: "High"},
+	StatusAccepted:    {status: "Open", severity: "Medium"},
+	StatusUntested:    {status: "Mitigated", severity: "Medium"},
+	StatusTransferred: {status: "NotApplicable", severity: "Low"},
+	StatusMitigated:   {status: "Mitigated", severity: "Low"},
+}
+
+// Subset of the OWASP Threat Dragon v2 model format, whose diagrams are AntV X6 graphs.
+type (
+	tdModel struct {
+		Version string    `json:"version"`
+		Summary tdSummary `json:"summary"`
+		Detail  tdDetail  `json:"detail"`
+	}
+	tdSummary struct {
+		Title       string `json:"title"`
+		Owner       string `json:"owner"`
+		Description string `json:"description"`
+		ID          int    `json:"id"`
+	}
+	tdDetail struct {
+		Contributors []string    `json:"contributors"`
+		Diagrams     []tdDiagram `json:"diagrams"`
+		DiagramTop   int         `json:"diagramTop"`
+		Reviewer     string      `json:"reviewer"`
+		ThreatTop    int         `json:"threatTop"`
+	}
+	tdDiagram struct {
+		ID          int      `json:"id"`
+		Title       string   `json:"title"`
+		DiagramType string   `json:"diagramType"`
+		Placeholder string   `json:"placeholder"`
+		Thumbnail   string   `json:"thumbnail"`
+		Version     string   `json:"version"`
+		Cells       []tdCell `json:"cells"`
+	}
+	// tdCell is a node, with a position and a size, or an edge, with a source and a target.
+	tdCell struct {
+		ID       string      `json:"id"`
+		Shape    string      `json:"shape"`
+		ZIndex   int         `json:"zIndex"`
+		Visible  bool        `json:"visible"`
+		Position *tdPosition `json:"position,omitempty"`
+		Size     *tdSize     `json:"size,omitempty"`
+		Source   *tdEnd      `json:"source,omitempty"`
+		Target   *tdEnd      `json:"target,omitempty"`
+		Labels   []string    `json:"labels,omitempty"`
+		Attrs    tdAttrs     `json:"attrs"`
+		Data     tdData      `json:"data"`
+	}
+	tdPosition struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+	tdSize struct {
+		Width  int `json:"width"`
+		Height int `json:"height"`
+	}
+	tdEnd struct {
+		Cell string `json:"cell"`
+	}
+	tdAttrs struct {
+		Text *tdText   `json:"text,omitempty"`
+		Body *tdStroke `json:"body,omitempty"`
+		Line *tdLine   `json:"line,omitempty"`
+	}
+	tdText struct {
+		Text string `json:"text"`
+	}
+	tdStroke struct {
+		Stroke      string  `json:"stroke"`
+		StrokeWidth float64 `json:"strokeWidth"`
+	}
+	tdLine struct {
+		Stroke       string   `json:"stroke"`
+		StrokeWidth  float64  `json:"strokeWidth"`
+		SourceMarker tdMarker `json:"sourceMarker"`
+		TargetMarker tdMarker `json:"targetMarker"`
+	}
+	tdMarker struct {
+		Name string `json:"name"`
+	}
+	tdData struct {
+		Type             string     `json:"type"`
+		Name             string     `json:"name"`
+		Description      string     `json:"description"`
+		OutOfScope       bool       `json:"outOfScope"`
+		ReasonOutOfScope string     `json:"reasonOutOfScope"`
+		HasOpenThreats   bool       `json:"hasOpenThreats"`
+		IsTrustBoundary  bool       `json:"isTrustBoundary,omitempty"`
+		IsBidirectional  bool       `json:"isBidirectional,omitempty"`
+		Protocol         string     `json:"protocol,omitempty"`
+		Threats          []tdThreat `json:"threats"`
+	}
+	tdThreat struct {
+		ID          string `json:"id"`
+		Title       string `json:"title"`
+		Status      string `json:"status"`
+		Severity    string `json:"severity"`
+		Type        string `json:"type"`
+		Description string `json:"description"`
+		Mitigation  string `json:"mitigation"`
+		ModelType   string `json:"modelType"`
+		New         bool   `json:"new"`
+		Number      int    `json:"number"`
+		Score       string `json:"score"`
+	}
+)
+
+// threatDragonReport returns the threat model as an OWASP Threat Dragon v2 model with a single diagram: components as
+// actors, data stores or processes, laid out in a column per trust boundary, connections as data flows, and the
+// status of every threat touching a component as one of its threats.
+func threatDragonReport(v *View) (string, error) {
+	threats := map[string][]tdThreat{}
+	number := 0
+	for _, cp := range v.Postures {
+		for _, p := range cp.Threats {
+			number++
+			t := tdThreats[p.Status]
+			threats[cp.Component] = append(threats[cp.Component], tdThreat{
+				ID:          tdID("threat", cp.Component, p.Threat),
+				Title:       p.Threat,
+				Status:      t.status,
+				Severity:    t.severity,
+				Type:        "Generic",
+				Description: tdDescription(p),
+				Mitigation:  strings.Join(p.Controls, "\n"),
+				ModelType:   "Generic",
+				Number:      number,
+				Score:       "",
+			})
+		}
+	}
+
+	// Group the components by trust boundary, in the order of the boundaries.
+	columns := map[string][]string{}
+	for _, name := range sortedNames(v.Library.Components) {
+		boundary := strings.Join(componentPath(v.Library, name), ":")
+		columns[boundary] = append(columns[boundary], name)
+	}
+	boundaries := sortedNames(columns)
+
+	var cells []tdCell
+	for i, boundary := range boundaries {
+		x := tdMargin + i*tdColumn
+		if boundary != "" {
+			cells = append(cells, tdCell{
+				ID:       tdID("boundary", boundary),
+				Shape:    "trust-boundary-box",
+				ZIndex:   -1,
+				Visible:  true,
+				Position: &tdPosition{X: x - tdMargin/2, Y: tdMargin / 2},
+				Size:     &tdSize{Width: tdColumn - tdMargin, Height: len(columns[boundary])*tdRow + tdMargin},
+				Attrs:    tdAttrs{Text: &tdText{Text: boundary}},
+				Data:     tdData{Type: "tm.BoundaryBox", Name: boundary, IsTrustBoundary: true, Threats: []tdThreat{}},
+			})
+		}
+
+		for row, name := range columns[boundary] {
+			c := v.Library.Components[name]
+			shape, typ, size := tdShape(c.Name)
+
+			data := tdData{Type: typ, Name: c.Name, Description: c.Description, Threats: threats[c.Name]}
+			if data.Threats == nil {
+				data.Threats = []tdThreat{}
+			}
+			stroke := "#333333"
+			for _, t := range data.Threats {
+				if t.Status == "Open" {
+					data.HasOpenThreats = true
+					stroke = "red"
+				}
+			}
+
+			cells = append(cells, tdCell{
+				ID:       tdID("component", c.Name),
+				Shape:    shape,
+				ZIndex:   1,
+				Visible:  true,
+				Position: &tdPosition{X: x, Y: tdMargin + row*tdRow},
+				Size:     &size,
+				Attrs:    tdAttrs{Text: &tdText{Text: c.Name}, Body: &tdStroke{Stroke: stroke, StrokeWidth: 1.5}},
+				Data:     data,
+			})
+		}
+	}
+
+	for i, c := range v.ThreatModel.Connections {
+		line := &tdLine{Stroke: "#333333", StrokeWidth: 1, TargetMarker: tdMarker{Name: "block"}}
+		if c.Direction == "with" {
+			line.SourceMarker = tdMarker{Name: "block"}
+		}
+
+		cells = append(cells, tdCell{
+			ID:      tdID("flow", fmt.Sprint(i), c.SourceComponent, c.DestinationComponent),
+			Shape:   "flow",
+			ZIndex:  10,
+			Visible: true,
+			Source:  &tdEnd{Cell: tdID("component", c.SourceComponent)},
+			Target:  &tdEnd{Cell: tdID("component", c.DestinationComponent)},
+			Labels:  []string{c.Details},
+			Attrs:   tdAttrs{Line: line},
+			Data: tdData{
+				Type:            "tm.Flow",
+				Name:            c.Details,
+				IsBidirectional: c.Direction == "with",
+				Threats:         []tdThreat{},
+			},
+		})
+	}
+
+	title := v.Project.Name + " threat model"
+	b, err := json.MarshalIndent(tdModel{
+		Version: threatDragonVersion,
+		Summary: tdSummary{Title: title, Description: v.Project.Description},
+		Detail: tdDetail{
+			Contributors: []string{},
+			Diagrams: []tdDiagram{{
+				Title:       title,
+				DiagramType: "Generic",
+				Placeholder: "Data-flow diagram generated from the annotations of the code",
+				Thumbnail:   "./public/content/images/thumbnail.jpg",
+				Version:     threatDragonVersion,
+				Cells:       cells,
+			}},
+			DiagramTop: 1,
+			ThreatTop:  number,
+		},
+	}, "", " ")
+	if err != nil {
+		return "", eris.Wrap(err, "failed to marshal Threat Dragon model")
+	}
+
+	return string(b) + "\n", nil
+}
+
+// tdShape returns the shape, the type and the size of the element of a component, guessed from the words of its name
+// without its trust boundaries.
+func tdShape(name string) (string, string, tdSize) {
+	elements := strings.Split(name, ":")
+	words := tdWords(elements[len(elements)-1])
+
+	// The last word says what the component is, like Service in UserService, unless all the words make up a keyword,
+	// like FileSystem.
+	var last, all string
+	if len(words) > 0 {
+		last, all = words[len(words)-1], strings.Join(words, "")
+	}
+
+	switch {
+	case tdActorKeywords[last] || tdActorKeywords[all]:
+		return "actor", "tm.Actor", tdSize{Width: 150, Height: 80}
+	case tdStoreKeywords[last] || tdStoreKeywords[all]:
+		return "store", "tm.Store", tdSize{Width: 150, Height: 75}
+	default:
+		return "process", "tm.Process", tdSize{Width: 100, Height: 100}
+	}
+}
+
+// tdWords splits a name into its lowercase words, separated by other characters than letters and digits or by a
+// change of case, like user, db and api in UserDB-API.
+func tdWords(name string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words, word = append(words, strings.ToLower(string(word))), nil
+			}
+			continue
+		}
+
+		// A word starts at an upper case letter following a lower case letter or a digit, or ending a run of upper case
+		// letters.
+		if len(word) > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+			(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			words, word = append(words, strings.ToLower(string(word))), nil
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, strings.ToLower(string(word)))
+	}
+
+	return words
+}
+
+// tdDescription describes the annotations the status of a threat against a component is computed from.
+func tdDescription(p ThreatPosture) string {
+	var lines []string
+	for _, e := range p.Exposures {
+		lines = append(lines, "Exposed with "+e.Details+tdSource(e.Source))
+	}
+	for _, a := range p.Acceptances {
+		lines = append(lines, "Accepted with "+a.Details+tdSource(a.Source))
+	}
+	for _, t := range p.Transfers {
+		lines = append(lines, "Transferred to "+t.DestinationComponent+" with "+t.Details+tdSource(t.Source))
+	}
+	for _, m := range p.Mitigations {
+		lines = append(lines, "Mitigated with "+m.Control+tdSource(m.Source))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// tdSource returns the location of an annotation to append to its description, or nothing if its source is unknown.
+func tdSource(source library.Source) string {
+	if source.Filename == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(" (%s:%d)", source.Filename, source.Line)
+}
+
+// tdID derives a stable name-based UUID from the kind and the names of an element, so that exports of the same threat
+// model are identical.
+func tdID(names ...string) string {
+	sum := sha1.Sum([]byte(strings.Join(names, "\x00"))) //nolint:gosec // only derives stable identifiers
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
@@ -0,0 +1,109 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestTDShape(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "User:Browser", want: "actor"},
+		{name: "Internet:Customer", want: "actor"},
+		{name: "WebApp:admin-client", want: "actor"},
+		{name: "WebApp:UserService", want: "process"},
+		{name: "WebApp:Login", want: "process"},
+		{name: "WebApp:Catalog", want: "process"},
+		{name: "WebApp:Profile", want: "process"},
+		{name: "Users:API", want: "process"},
+		{name: "WebApp:UserDB", want: "store"},
+		{name: "WebApp:FileSystem", want: "store"},
+		{name: "WebApp:file_system", want: "store"},
+		{name: "WebApp:Audit log", want: "store"},
+		{name: "AWS:S3Bucket", want: "store"},
+		{name: "WebApp:DBProxy", want: "process"},
+		{name: "Web", want: "process"},
+		{name: "", want: "process"},
+	}
+
+	for _, tt := range tests {
+		if got, _, _ := tdShape(tt.name); got != tt.want {
+			t.Errorf("tdShape(%q) = %q, want %q (words %q)", tt.name, got, tt.want, tdWords(tt.name))
+		}
+	}
+}
+
+func TestTDWords(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "UserDB-API", want: []string{"user", "db", "api"}},
+		{name: "DBProxy", want: []string{"db", "proxy"}},
+		{name: "S3Bucket", want: []string{"s3", "bucket"}},
+		{name: "audit_log v2", want: []string{"audit", "log", "v2"}},
+		{name: "--"},
+	}
+
+	for _, tt := range tests {
+		if got := tdWords(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tdWords(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTDDescription(t *testing.T) {
+	tests := []struct {
+		name    string
+		posture ThreatPosture
+		want    string
+	}{
+		{
+			name: "annotated",
+			posture: ThreatPosture{
+				Exposures:   []library.Exposure{{Details: "raw queries", Source: library.Source{Filename: "db.go", Line: 3}}},
+				Mitigations: []library.Mitigate{{Control: "prepared statements", Source: library.Source{Filename: "db.go", Line: 9}}},
+			},
+			want: "Exposed with raw queries (db.go:3)\nMitigated with prepared statements (db.go:9)",
+		},
+		{
+			name: "imported",
+			posture: ThreatPosture{
+				Acceptances: []library.Acceptance{{Details: "low impact"}},
+				Transfers:   []library.Transfer{{DestinationComponent: "WebApp:CDN", Details: "cached"}},
+			},
+			want: "Accepted with low impact\nTransferred to WebApp:CDN with cached",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tdDescription(tt.posture); got != tt.want {
+				t.Errorf("tdDescription() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThreatDragonReport(t *testing.T) {
+	v := newView(newLibrary(library.Threatmodel{
+		Connections: []library.Connection{{SourceComponent: "User:Browser", DestinationComponent: "WebApp:Web", Direction: "to", Details: "HTTPS"}},
+		Exposures:   []library.Exposure{{Threat: "XSS", Component: "WebApp:Web", Details: "unescaped names"}},
+	}))
+
+	first, err := threatDragonReport(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := threatDragonReport(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("threatDragonReport() differs between two exports of the same threat model")
+	}
+}
@@ -0,0 +1,114 @@
+package report
+
+import (
+	"encoding/json"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+// threatspecPages returns the library and the threat model as the output files of threatspec, for its reporting:
+// the libraries indexed by threatspec id under their name, and the threat model referencing the entries by id.
+func threatspecPages(v *View) (map[string]string, error) {
+	// The annotations reference the library entries by name, mapped to their threatspec id.
+	threatIDs, controlIDs, componentIDs := map[string]string{}, map[string]string{}, map[string]string{}
+
+	threats := map[string]library.Threat{}
+	for _, t := range v.Threats {
+		name := t.Name
+		t.Name, t.Id = library.ThreatspecEntry(t.Name, t.Id)
+		threats[t.Id], threatIDs[name] = t, t.Id
+	}
+	controls := map[string]library.Control{}
+	for _, c := range v.Library.Controls {
+		name := c.Name
+		c.Name, c.Id = library.ThreatspecEntry(c.Name, c.Id)
+		controls[c.Id], controlIDs[name] = c, c.Id
+	}
+	components := map[string]library.Component{}
+	for _, c := range v.Library.Components {
+		name := c.Name
+		c.Name, c.Id = library.ThreatspecEntry(c.Name, c.Id)
+		components[c.Id], componentIDs[name] = c, c.Id
+	}
+
+	id := func(ids map[string]string, name string) string {
+		if id, ok := ids[name]; ok {
+			return id
+		}
+		_, id := library.ThreatspecID(name)
+
+		return id
+	}
+	threat := func(name string) string { return id(threatIDs, name) }
+	control := func(name string) string { return id(controlIDs, name) }
+	component := func(name string) string { return id(componentIDs, name) }
+
+	tm := threatspecThreatmodel{
+		Mitigations: []library.Mitigate{},
+		Exposures:   []library.Exposure{},
+		Transfers:   []library.Transfer{},
+		Acceptances: []library.Acceptance{},
+		Connections: []library.Connection{},
+		Reviews:     []library.Review{},
+		Tests:       []library.Test{},
+		RunId:       v.ThreatModel.RunId,
+	}
+	for _, m := range v.ThreatModel.Mitigations {
+		m.Control, m.Threat, m.Component = control(m.Control), threat(m.Threat), component(m.Component)
+		tm.Mitigations = append(tm.Mitigations, m)
+	}
+	for _, e := range v.ThreatModel.Exposures {
+		e.Threat, e.Component = threat(e.Threat), component(e.Component)
+		tm.Exposures = append(tm.Exposures, e)
+	}
+	for _, t := range v.ThreatModel.Transfers {
+		t.Threat, t.SourceComponent, t.DestinationComponent = threat(t.Threat), component(t.SourceComponent), component(t.DestinationComponent)
+		tm.Transfers = append(tm.Transfers, t)
+	}
+	for _, a := range v.ThreatModel.Acceptances {
+		a.Threat, a.Component = threat(a.Threat), component(a.Component)
+		tm.Acceptances = append(tm.Acceptances, a)
+	}
+	for _, c := range v.ThreatModel.Connections {
+		c.SourceComponent, c.DestinationComponent = component(c.SourceComponent), component(c.DestinationComponent)
+		tm.Connections = append(tm.Connections, c)
+	}
+	for _, r := range v.ThreatModel.Reviews {
+		r.Component = component(r.Component)
+		tm.Reviews = append(tm.Reviews, r)
+	}
+	for _, t := range v.ThreatModel.Tests {
+		t.Component, t.Control = component(t.Component), control(t.Control)
+		tm.Tests = append(tm.Tests, t)
+	}
+
+	pages := map[string]string{}
+	for filename, content := range map[string]interface{}{
+		"threats.json":     library.ThreatspecThreats{Threats: threats},
+		"controls.json":    library.ThreatspecControls{Controls: controls},
+		"components.json":  library.ThreatspecComponents{Components: components},
+		"threatmodel.json": tm,
+	} {
+		b, err := json.MarshalIndent(content, "", " ")
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to marshal %s", filename)
+		}
+		pages[filename] = string(b) + "\n"
+	}
+
+	return pages, nil
+}
+
+// threatspecThreatmodel is the threat model as threatspec records it, without scope.
+type threatspecThreatmodel struct {
+	Mitigations []library.Mitigate   `json:"mitigations"`
+	Exposures   []library.Exposure   `json:"exposures"`
+	Transfers   []library.Transfer   `json:"transfers"`
+	Acceptances []library.Acceptance `json:"acceptances"`
+	Connections []library.Connection `json:"connections"`
+	Reviews     []library.Review     `json:"reviews"`
+	Tests       []library.Test       `json:"tests"`
+	RunId       string               `json:"run_id"`
+}
@@ -0,0 +1,110 @@
+package report
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+type (
+	// Trend is the evolution of the threat model across the snapshots of its history.
+	Trend struct {
+		// Points count the annotations of each snapshot, the oldest first.
+		Points []TrendPoint `json:"points"`
+		// Previous is the time of the snapshot the threat model is compared to, zero without history.
+		Previous time.Time `json:"previous"`
+		// Added and Removed are the annotations added and removed since the previous snapshot.
+		Added   []string `json:"added"`
+		Removed []string `json:"removed"`
+	}
+	// TrendPoint counts the annotations of a snapshot.
+	TrendPoint struct {
+		Taken       time.Time `json:"taken"`
+		Exposures   int       `json:"exposures"`
+		Mitigations int       `json:"mitigations"`
+		Acceptances int       `json:"acceptances"`
+		// Residual counts the threats components are exposed to without mitigation, or which were accepted.
+		Residual int `json:"residual"`
+	}
+)
+
+// newTrend computes the trend of the threat model from its history. The latest snapshot is normally the current
+// threat model, archived by run, in which case the threat model is compared to the snapshot before it.
+func newTrend(tm library.Threatmodel, snapshots []library.Snapshot) Trend {
+	var t Trend
+	for _, s := range snapshots {
+		residual := 0
+		for _, cp := range postures(&library.Library{ThreatModel: s.ThreatModel}) {
+			residual += cp.Residual
+		}
+
+		t.Points = append(t.Points, TrendPoint{
+			Taken:       s.Taken,
+			Exposures:   len(s.ThreatModel.Exposures),
+			Mitigations: len(s.ThreatModel.Mitigations),
+			Acceptances: len(s.ThreatModel.Acceptances),
+			Residual:    residual,
+		})
+	}
+
+	if len(snapshots) > 0 && snapshots[len(snapshots)-1].ThreatModel.Same(tm) {
+		snapshots = snapshots[:len(snapshots)-1]
+	}
+	if len(snapshots) == 0 {
+		return t
+	}
+
+	previous := snapshots[len(snapshots)-1]
+	t.Previous = previous.Taken
+	t.Added, t.Removed = difference(tm.Keys(), previous.ThreatModel.Keys())
+
+	return t
+}
+
+// difference returns the keys only in current and the keys only in previous, both sorted.
+func difference(current, previous []string) (added, removed []string) {
+	count := map[string]int{}
+	for _, key := range previous {
+		count[key]++
+	}
+	for _, key := range current {
+		if count[key] > 0 {
+			count[key]--
+			continue
+		}
+		added = append(added, key)
+	}
+
+	count = map[string]int{}
+	for _, key := range current {
+		count[key]++
+	}
+	for _, key := range previous {
+		if count[key] > 0 {
+			count[key]--
+			continue
+		}
+		removed = append(removed, key)
+	}
+
+	return added, removed
+}
+
+// trendTable returns the counts of the snapshots as a Markdown table.
+func trendTable(points []TrendPoint) string {
+	t := newRecordTable(len(points), "Snapshot", "Exposures", "Mitigations", "Acceptances", "Residual risks")
+	for col := 1; col <= 4; col++ {
+		t.SetAlignment(col, AlignRight)
+	}
+
+	for row, p := range points {
+		t.SetContent(row, 0, p.Taken.Format(time.RFC3339)).
+			SetContent(row, 1, strconv.Itoa(p.Exposures)).
+			SetContent(row, 2, strconv.Itoa(p.Mitigations)).
+			SetContent(row, 3, strconv.Itoa(p.Acceptances)).
+			SetContent(row, 4, strconv.Itoa(p.Residual))
+	}
+
+	return t.String()
+}
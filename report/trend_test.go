@@ -0,0 +1,67 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+func TestNewTrend(t *testing.T) {
+	xss := library.Exposure{Threat: "XSS", Component: "WebApp:Web", Details: "unescaped names"}
+	sqli := library.Exposure{Threat: "SQLi", Component: "WebApp:DB", Details: "raw queries"}
+	escaping := library.Mitigate{Threat: "XSS", Component: "WebApp:Web", Control: "escaping"}
+
+	first := library.Snapshot{Taken: time.Unix(1, 0).UTC(), ThreatModel: library.Threatmodel{Exposures: []library.Exposure{xss, sqli}}}
+	second := library.Snapshot{Taken: time.Unix(2, 0).UTC(), ThreatModel: library.Threatmodel{Exposures: []library.Exposure{xss}, Mitigations: []library.Mitigate{escaping}}}
+	current := second.ThreatModel
+
+	tests := []struct {
+		name      string
+		tm        library.Threatmodel
+		snapshots []library.Snapshot
+		want      Trend
+	}{
+		{name: "no history", tm: current},
+		{
+			name:      "current threat model archived",
+			tm:        current,
+			snapshots: []library.Snapshot{first, second},
+			want: Trend{
+				Points: []TrendPoint{
+					{Taken: first.Taken, Exposures: 2, Residual: 2},
+					{Taken: second.Taken, Exposures: 1, Mitigations: 1},
+				},
+				Previous: first.Taken,
+				Added:    []string{"mitigates WebApp:Web against XSS with escaping"},
+				Removed:  []string{"exposes WebApp:DB to SQLi with raw queries"},
+			},
+		},
+		{
+			name:      "current threat model not archived",
+			tm:        current,
+			snapshots: []library.Snapshot{first},
+			want: Trend{
+				Points:   []TrendPoint{{Taken: first.Taken, Exposures: 2, Residual: 2}},
+				Previous: first.Taken,
+				Added:    []string{"mitigates WebApp:Web against XSS with escaping"},
+				Removed:  []string{"exposes WebApp:DB to SQLi with raw queries"},
+			},
+		},
+		{
+			name:      "only the current threat model archived",
+			tm:        current,
+			snapshots: []library.Snapshot{second},
+			want:      Trend{Points: []TrendPoint{{Taken: second.Taken, Exposures: 1, Mitigations: 1}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTrend(tt.tm, tt.snapshots); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newTrend() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
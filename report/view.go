@@ -0,0 +1,142 @@
+package report
+
+import (
+	"time"
+
+	"github.com/morphysm/famed-annotated/config"
+	"github.com/morphysm/famed-annotated/library"
+)
+
+type (
+	// View is the data the report templates are executed against. Its fields are documented for the authors of
+	// custom templates, see the embedded templates/report.md.tmpl for an example.
+	View struct {
+		// Project is the project metadata from the configuration file.
+		Project Project
+		// RepositoryURL is the web URL of the repository, from the configuration file.
+		RepositoryURL string
+		// Commit is the SHA of the commit checked out in the repository, the source links are pinned to.
+		Commit string
+		// Generated is the time the report is generated at.
+		Generated time.Time
+		// Library holds the components, controls and threats, indexed by id.
+		Library *library.Library
+		// Threats are the threats of the library, indexed by id, without the imported CWE weaknesses and CAPEC attack
+		// patterns the threat model does not reference.
+		Threats map[string]library.Threat
+		// ThreatModel holds the annotations: mitigations, exposures, acceptances, transfers, connections, reviews and tests.
+		ThreatModel library.Threatmodel
+		// Statistics counts the entries of the library and the threat model.
+		Statistics Statistics
+		// Mermaid is the data-flow diagram as a Mermaid flowchart.
+		Mermaid string
+		// Image is the path of the data-flow diagram rendered by Graphviz, empty if it could not be rendered.
+		Image string
+		// Crossings are the connections and transfers crossing a trust boundary.
+		Crossings []Crossing
+		// Assurance measures the mitigations verified by a test.
+		Assurance Assurance
+		// Summary holds the headline metrics of the executive summary.
+		Summary Summary
+		// Trend is the evolution of the threat model across the snapshots of its history.
+		Trend Trend
+		// Matrix is the threat × component coverage matrix.
+		Matrix *Matrix
+		// Postures are the risk postures of the components touched by a threat, the riskiest components first.
+		Postures []ComponentPosture
+		// Weaknesses are the threats the components are exposed to with the attack patterns of the library exploiting
+		// them, when CAPEC attack patterns are imported.
+		Weaknesses []ExposedWeakness
+
+		sourceURLPattern string
+		// imagePath is the path of the rendered diagram from the current directory, Image being relative to the report.
+		imagePath string
+		// outputDir is the directory of the document being written, the source links are relative to.
+		outputDir string
+	}
+	// Project is the project metadata.
+	Project struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	// Statistics counts the entries of the library and the threat model.
+	Statistics struct {
+		Components  int `json:"components"`
+		Controls    int `json:"controls"`
+		Threats     int `json:"threats"`
+		Mitigations int `json:"mitigations"`
+		Exposures   int `json:"exposures"`
+		Acceptances int `json:"acceptances"`
+		Transfers   int `json:"transfers"`
+		Connections int `json:"connections"`
+		Reviews     int `json:"reviews"`
+		Tests       int `json:"tests"`
+	}
+	// Crossing is a connection or a transfer crossing a trust boundary.
+	Crossing struct {
+		From         string `json:"from"`
+		To           string `json:"to"`
+		FromBoundary string `json:"from_boundary"`
+		ToBoundary   string `json:"to_boundary"`
+		Details      string `json:"details"`
+	}
+)
+
+// NewView returns the view of the library and of the project configuration.
+func NewView(l *library.Library, cfg *config.Config, generated time.Time) (*View, error) {
+	threats := l.AssessedThreats()
+	v := &View{
+		Project: Project{
+			Name:        cfg.Project.Name,
+			Description: cfg.Project.Description,
+		},
+		RepositoryURL: cfg.RepositoryURL,
+		Commit:        headCommit(),
+		Generated:     generated,
+		Library:       l,
+		Threats:       threats,
+		ThreatModel:   l.ThreatModel,
+		Statistics: Statistics{
+			Components:  len(l.Components),
+			Controls:    len(l.Controls),
+			Threats:     len(threats),
+			Mitigations: len(l.ThreatModel.Mitigations),
+			Exposures:   len(l.ThreatModel.Exposures),
+			Acceptances: len(l.ThreatModel.Acceptances),
+			Transfers:   len(l.ThreatModel.Transfers),
+			Connections: len(l.ThreatModel.Connections),
+			Reviews:     len(l.ThreatModel.Reviews),
+			Tests:       len(l.ThreatModel.Tests),
+		},
+		Mermaid:  Mermaid(l),
+		Postures: postures(l),
+		Matrix:   NewMatrix(l),
+
+		Assurance: newAssurance(l.ThreatModel),
+
+		sourceURLPattern: sourceURLPattern(cfg.RepositoryURL, cfg.RepositoryURLPattern),
+	}
+
+	for _, e := range newDiagram(l).edges {
+		if e.crossing {
+			v.Crossings = append(v.Crossings, Crossing{
+				From:         e.from.name,
+				To:           e.to.name,
+				FromBoundary: e.from.cluster.boundary(),
+				ToBoundary:   e.to.cluster.boundary(),
+				Details:      e.label,
+			})
+		}
+	}
+
+	v.Weaknesses = exposedWeaknesses(l, v.Postures)
+	v.Summary = newSummary(v)
+
+	snapshots, err := library.ReadSnapshots(cfg.ThreatModelDir)
+	if err != nil {
+		return nil, err
+	}
+	v.Trend = newTrend(l.ThreatModel, snapshots)
+
+	return v, nil
+}
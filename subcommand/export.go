@@ -0,0 +1,26 @@
+package subcommand
+
+import (
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/report"
+)
+
+type Export struct {
+	Format string `short:"f" enum:"csv" default:"csv" help:"Format of the exported files, one of: csv."`
+	Output string `short:"o" default:"export" help:"Directory to write the exported files to."`
+}
+
+// Help shows the Export subcommand help.
+func (*Export) Help() string {
+	return "This will write the records of the threat model and of its library as registers for\n    spreadsheets and GRC platforms, one file per type of record in the output directory:\n    mitigations, exposures, acceptances, transfers, connections, reviews and tests, then\n    threats, controls and components.\n    \n    CSV files are quoted as per RFC 4180. Their columns are stable: the fields of the\n    record, then its custom data flattened into custom.<key> columns, sorted by key."
+}
+
+// Run exports the records of the threat model.
+func (a *Export) Run() error {
+	if err := report.FileExport(a.Format, a.Output); err != nil {
+		return eris.Wrap(err, "failed to export threat model")
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package subcommand
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/phuslu/log"
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/config"
+	"github.com/morphysm/famed-annotated/library"
+)
+
+type Import struct {
+	From string `enum:"otm,threatspec" required:"" help:"Format of the model to import, one of: otm, threatspec."`
+	Path string `arg:"" type:"path" help:"Model to import: an OTM file or a threatspec project directory."`
+}
+
+// Help shows the Import subcommand help.
+func (*Import) Help() string {
+	return "This will add the threats, controls and components of a threat model drawn in\n    another tool to the library files, seeding the library before annotating code:\n    threatmodel/threats.json threatmodel/controls.json threatmodel/components.json\n    Existing entries of the library are kept, and imported entries are marked as seeded:\n    famed-annotated run keeps them even when no annotation references them.\n    \n    The --from flag selects the format of the model:\n        otm         an Open Threat Model JSON file. Its threats and mitigations become\n                    threats and controls, and its components are named after the trust\n                    zones and components they are nested in, like WebApp:Web.\n        threatspec  a threatspec project directory. The project, imports and paths of its\n                    threatspec.yaml are set in famed-annotated.yml, created if missing, and\n                    its library entries keep their ids, like #sqli. The threat model is\n                    not imported: famed-annotated run rebuilds it from the annotations."
+}
+
+// importers associates the supported model formats with the function adding the model to the library.
+var importers = map[string]func(l *library.Library, path string) error{
+	"otm":        (*library.Library).ImportOTM,
+	"threatspec": (*library.Library).ImportThreatspec,
+}
+
+// Run adds the imported model to the library files.
+func (a *Import) Run() error {
+	if a.From == "threatspec" {
+		if err := importThreatspecConfig(a.Path); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := config.LoadFile()
+	if err != nil {
+		return err
+	}
+
+	l := library.Library{
+		Components: map[string]library.Component{},
+		Controls:   map[string]library.Control{},
+		Threats:    map[string]library.Threat{},
+	}
+	if err := l.ReadLibraryFiles(cfg.ThreatModelDir); err != nil {
+		return err
+	}
+
+	if err := importers[a.From](&l, a.Path); err != nil {
+		return eris.Wrapf(err, "failed to import %s", a.Path)
+	}
+
+	if err := l.SaveLibraryFiles(cfg.ThreatModelDir); err != nil {
+		return err
+	}
+
+	log.Info().Int("threats", len(l.Threats)).Int("controls", len(l.Controls)).Int("components", len(l.Components)).
+		Msgf("library seeded from %s", a.Path)
+
+	return nil
+}
+
+// importThreatspecConfig sets the configuration file from the threatspec.yaml file of a threatspec project, if any.
+func importThreatspecConfig(dir string) error {
+	filename := filepath.Join(dir, "threatspec.yaml")
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil
+	}
+
+	ignored, err := config.ImportThreatspec(filename)
+	if err != nil {
+		return eris.Wrap(err, "failed to import threatspec configuration")
+	}
+	for _, path := range ignored {
+		log.Warn().Str("path", path).Msg("ignore patterns of threatspec paths are not supported, the path is searched in full")
+	}
+	log.Info().Msgf("famed-annotated.yml configured from %s", filename)
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package subcommand
+
+import (
+	"github.com/phuslu/log"
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/config"
+	"github.com/morphysm/famed-annotated/library"
+)
+
+type Library struct {
+	Import LibraryImport `cmd:"" help:"Import a catalogue into the threat model library."`
+}
+
+type LibraryImport struct {
+	CWE   LibraryImportCWE   `cmd:"" name:"cwe" help:"Import the MITRE CWE catalogue as threats."`
+	CAPEC LibraryImportCAPEC `cmd:"" name:"capec" help:"Import the MITRE CAPEC attack patterns as threats linked to CWE weaknesses."`
+}
+
+type LibraryImportCWE struct {
+	Path string `arg:"" type:"existingfile" help:"CWE XML catalogue, like cwec_v4.14.xml, downloaded from https://cwe.mitre.org/data/downloads.html."`
+	View string `help:"Only import the weaknesses of a view, like 1425 or CWE-1425 for a CWE Top 25."`
+}
+
+// Help shows the library import cwe subcommand help.
+func (*LibraryImportCWE) Help() string {
+	return "This will add the weaknesses of a locally downloaded MITRE CWE XML catalogue to\n    threatmodel/threats.json, with ids like #cwe-79, names and descriptions. Their\n    likelihood of exploit, related weaknesses and attack patterns and their page on the\n    CWE website are kept as custom data. Existing threats are kept, and completed.\n    \n    Annotations then reference the weaknesses by id, and the next famed-annotated run\n    resolves them to their name, for example:\n        @exposes WebApp:Web to #cwe-79 with unescaped user names"
+}
+
+// Run adds the weaknesses of the catalogue to the library files.
+func (a *LibraryImportCWE) Run() error {
+	cfg, err := config.LoadFile()
+	if err != nil {
+		return err
+	}
+
+	l, err := readLibraryFiles(cfg.ThreatModelDir)
+	if err != nil {
+		return err
+	}
+
+	imported, err := l.ImportCWE(a.Path, a.View)
+	if err != nil {
+		return eris.Wrapf(err, "failed to import %s", a.Path)
+	}
+
+	if err := l.SaveLibraryFiles(cfg.ThreatModelDir); err != nil {
+		return err
+	}
+	log.Info().Int("threats", imported).Msgf("CWE weaknesses imported from %s", a.Path)
+
+	return nil
+}
+
+type LibraryImportCAPEC struct {
+	Path   string `arg:"" type:"existingfile" help:"CAPEC XML catalogue, like capec_v3.9.xml, downloaded from https://capec.mitre.org/data/downloads.html."`
+	Linked bool   `help:"Only import the attack patterns exploiting a weakness of the library, imported from CWE beforehand."`
+}
+
+// Help shows the library import capec subcommand help.
+func (*LibraryImportCAPEC) Help() string {
+	return "This will add the attack patterns of a locally downloaded MITRE CAPEC XML catalogue to\n    threatmodel/threats.json, with ids like #capec-63, names and descriptions. The CWE\n    weaknesses they exploit are kept as custom data with ids like #cwe-79, along with their\n    mitigations as candidate controls, their likelihood, severity and page on the CAPEC\n    website. Existing threats are kept, and completed.\n    \n    The report then lists the attack patterns exploiting the weaknesses the components are\n    exposed to, for example after:\n        @exposes WebApp:Web to #cwe-79 with unescaped user names"
+}
+
+// Run adds the attack patterns of the catalogue to the library files.
+func (a *LibraryImportCAPEC) Run() error {
+	cfg, err := config.LoadFile()
+	if err != nil {
+		return err
+	}
+
+	l, err := readLibraryFiles(cfg.ThreatModelDir)
+	if err != nil {
+		return err
+	}
+
+	imported, err := l.ImportCAPEC(a.Path, a.Linked)
+	if err != nil {
+		return eris.Wrapf(err, "failed to import %s", a.Path)
+	}
+
+	if err := l.SaveLibraryFiles(cfg.ThreatModelDir); err != nil {
+		return err
+	}
+	log.Info().Int("threats", imported).Msgf("CAPEC attack patterns imported from %s", a.Path)
+
+	return nil
+}
+
+// readLibraryFiles returns the library of the threat model directory, empty if it has none yet.
+func readLibraryFiles(dir string) (*library.Library, error) {
+	l := &library.Library{
+		Components: map[string]library.Component{},
+		Controls:   map[string]library.Control{},
+		Threats:    map[string]library.Threat{},
+	}
+	if err := l.ReadLibraryFiles(dir); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
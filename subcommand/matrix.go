@@ -0,0 +1,31 @@
+package subcommand
+
+import (
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/report"
+)
+
+type Matrix struct {
+	Format string `short:"f" enum:"csv,md" default:"csv" help:"Format of the matrix, one of: csv, md."`
+	Output string `short:"o" help:"File to write the matrix to, defaults to matrix.csv or matrix.md."`
+}
+
+// Help shows the Matrix subcommand help.
+func (*Matrix) Help() string {
+	return "This will cross every threat in threatmodel/threats.json with every component in\n    threatmodel/components.json, and write the coverage matrix as CSV or as a Markdown\n    table. Each cell is M, E, A or T when the threat is respectively mitigated, exposed,\n    accepted or transferred for the component, and blank when it was never assessed."
+}
+
+// Run writes the threat × component coverage matrix.
+func (a *Matrix) Run() error {
+	output := a.Output
+	if output == "" {
+		output = "matrix." + a.Format
+	}
+
+	if err := report.FileMatrix(a.Format, output); err != nil {
+		return eris.Wrap(err, "failed to generate matrix file")
+	}
+
+	return nil
+}
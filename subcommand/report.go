@@ -6,14 +6,31 @@ import (
 	"github.com/morphysm/famed-annotated/report"
 )
 
-type Report struct{}
+type Report struct {
+	Formats        []string `name:"format" short:"f" enum:"md,html,json,csv,sarif,junit,otm,threatdragon,site,threatspec" default:"md" help:"Format of the report, one of: md, html, json, csv, sarif, junit, otm, threatdragon, site, threatspec. Can be repeated."`
+	Output         string   `short:"o" help:"File to write the report to, - for the standard output. With several formats, the extension of each format replaces its own, and directories are named like report-site."`
+	ThreatModelDir string   `name:"threatmodel-dir" short:"d" type:"existingdir" help:"Directory to read the threat model from, overrides the threatmodel_dir configuration key."`
+	Templates      []string `name:"template" short:"t" type:"existingfile" help:"Generate the report with a custom text/template file instead, overrides the report.templates configuration key. Can be repeated."`
+	Timestamp      string   `help:"Date of the report as a Unix timestamp or an RFC 3339 date, defaults to SOURCE_DATE_EPOCH or the current time."`
+}
 
 func (*Report) Help() string {
-	return "This will by default use Graphviz to generate a visualisation of the threat model,\n    and embed it in a threat model markdown document in the current directory:\n    \n    ThreatModel.md\n    This document contains tables of mitigations etc (including any tests), as\n    well as connections and reviews."
+	return "This will by default use Graphviz to generate a visualisation of the threat model,\n    written to threatmodel/threatmodel.dot and rendered to SVG and PNG when the dot binary\n    is available, and embed it in a threat model markdown document in the current directory:\n    \n    report.md\n    This document contains tables of mitigations etc (including any tests), as\n    well as connections and reviews. The diagram is only written for the md, html and\n    site formats and custom templates, which embed it.\n    \n    The --format flag selects the format of the report and can be repeated to generate\n    several reports at once:\n        md    the markdown document, report.md\n        html  a self-contained document with a searchable and filterable view of every\n              annotation, report.html\n        json  the threat model and its analyses, report.json\n        csv   the threat × component coverage matrix, report.csv\n        sarif exposures and untested mitigations as SARIF 2.1.0 results for code scanning\n              tools, report.sarif\n        junit the checks that every exposure is mitigated, every mitigation has a test and\n              every acceptance is justified as JUnit XML test cases, report.junit.xml\n        otm   the threat model as an Open Threat Model document, report.otm\n        threatdragon\n              the threat model as an OWASP Threat Dragon v2 model, report.threatdragon.json\n        site  a documentation site of markdown pages with front matter, one per component,\n              threat and control, for MkDocs or Hugo, in the site directory\n        threatspec\n              the library and threat model files of threatspec, for its reporting, in the\n              threatspec directory\n    The --output flag sets the file to write to, or - for the standard output. With several\n    formats, the extension of each format replaces the one of the output, and the site and\n    threatspec directories are named after it, like report-site.\n    \n    With --template, or the report.templates configuration key, each given Go text/template\n    file is executed against the report view model instead of the default markdown\n    template, and written named after the template without its .tmpl extension. A report\n    which would overwrite its own template is refused.\n    \n    The report is reproducible: given the same threat model and --timestamp, or\n    SOURCE_DATE_EPOCH, it is byte-for-byte identical."
 }
 
 func (a *Report) Run() error {
-	err := report.FileReport()
+	generated, err := timestamp(a.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	err = report.FileReport(report.Options{
+		Formats:        a.Formats,
+		Output:         a.Output,
+		ThreatModelDir: a.ThreatModelDir,
+		Templates:      a.Templates,
+		Generated:      generated,
+	})
 	if err != nil {
 		return eris.Wrap(err, "failed to generate report file")
 	}
@@ -1,26 +1,35 @@
 package subcommand
 
 import (
-	"go/parser"
-	"go/token"
 	"io/fs"
 	"os"
 	"path/filepath"
 
+	"github.com/phuslu/log"
+	"github.com/rotisserie/eris"
+
 	"github.com/morphysm/famed-annotated/config"
 	"github.com/morphysm/famed-annotated/library"
 )
 
-type Run struct{}
+type Run struct {
+	Timestamp string `help:"Date of the history snapshot as a Unix timestamp or an RFC 3339 date, defaults to SOURCE_DATE_EPOCH or the current time."`
+}
 
 // Help show the Run subcommand help.
 func (*Run) Help() string {
-	return "This command loads the configuration file and for each configured path it first\n    checks to see if a famed-annotated.yaml file exists in the path. If it does, it loads\n    the three library json files.\n    Once all the library files have been loaded from the paths, famed-annotated run will\n    recursively parse each file in the path, looking for famed-annotated annotations.\n    \n    You can exclude patterns from being searched (for example 'node_modules') using the\n    'ignore' key for the paths in the configuration file. See the documentation for\n    more information.\n    After all the source files have parsed, famed-annotated run will generate the\n    threatmodel/threatmodel.json file as well as the three library files:\n    threatmodel/threats.json threatmodel/controls.json threatmodel/components.json"
+	return "This command loads the configuration file and for each configured path it first\n    checks to see if a famed-annotated.yaml file exists in the path. If it does, it loads\n    the three library json files.\n    Once all the library files have been loaded from the paths, famed-annotated run will\n    recursively parse each file in the path, looking for famed-annotated annotations.\n    \n    You can exclude patterns from being searched (for example 'node_modules') using the\n    'ignore' key for the paths in the configuration file. See the documentation for\n    more information.\n    After all the source files have parsed, famed-annotated run will generate the\n    threatmodel/threatModel.json file as well as the three library files:\n    threatmodel/threats.json threatmodel/controls.json threatmodel/components.json\n    The threatmodel directory can be changed with the threatmodel_dir configuration key.\n    \n    When the annotations changed since the previous run, a compressed snapshot of the\n    threat model is archived in threatmodel/history/, keeping the number of snapshots\n    set by the history.retention configuration key. Set history.disabled to true to\n    disable the history."
 }
 
 // Run starts the source code search process based on the file extension and generates the pre-report in json format.
 func (a *Run) Run() error {
-	if _, err := config.LoadFile(); err != nil {
+	cfg, err := config.LoadFile()
+	if err != nil {
+		return err
+	}
+
+	taken, err := timestamp(a.Timestamp)
+	if err != nil {
 		return err
 	}
 
@@ -30,28 +39,84 @@ func (a *Run) Run() error {
 		Threats:    map[string]library.Threat{},
 	}
 
-	// Pares every go files.
-	for _, s := range find("./", ".go") {
-		dat, _ := os.ReadFile(s)
-		f, _ := parser.ParseFile(token.NewFileSet(), "", dat, parser.ParseComments)
+	// Keep the threats, controls and components of the library seeded by hand or imported.
+	if err := l.ReadSeeds(cfg.ThreatModelDir); err != nil {
+		return err
+	}
 
-		for _, i := range f.Comments {
-			l.Parse(i.Text())
+	paths := cfg.Paths
+	if len(paths) == 0 {
+		paths = []string{"./"}
+	}
+	l.ThreatModel.Scope = library.Scope{Paths: paths, Languages: map[string]int{}}
+
+	// Parse every supported source file of the configured paths.
+	parsed := map[string]bool{}
+	for _, path := range paths {
+		for _, s := range find(path, parsers) {
+			if parsed[s] {
+				continue
+			}
+			parsed[s] = true
+
+			dat, err := os.ReadFile(s)
+			if err != nil {
+				return eris.Wrapf(err, "failed to read %s", s)
+			}
+
+			if err := parsers[filepath.Ext(s)](&l, s, dat); err != nil {
+				log.Warn().Err(err).Msg("skipping file")
+				continue
+			}
+
+			l.ThreatModel.Scope.Files++
+			l.ThreatModel.Scope.Languages[languages[filepath.Ext(s)]]++
 		}
 	}
 
-	l.SaveFiles()
+	if err := l.SaveFiles(cfg.ThreatModelDir); err != nil {
+		return err
+	}
+
+	if cfg.History.Disabled {
+		return nil
+	}
+
+	saved, err := l.SaveSnapshot(cfg.ThreatModelDir, taken, cfg.History.Retention)
+	if err != nil {
+		return eris.Wrap(err, "failed to archive the threat model")
+	}
+	if saved {
+		log.Info().Msg("threat model archived in the history")
+	}
 
 	return nil
 }
 
-func find(root, ext string) []string {
+// parseFunc adds to the library the annotations found in a source file.
+type parseFunc func(l *library.Library, filename string, data []byte) error
+
+// parsers associates the supported file extensions with the parser of their annotations.
+var parsers = map[string]parseFunc{
+	".go":     (*library.Library).ParseGo,
+	".tmpl":   (*library.Library).ParseTemplate,
+	".gohtml": (*library.Library).ParseTemplate,
+}
+
+// languages associates the supported file extensions with the name of their language.
+var languages = map[string]string{
+	".go":     "Go",
+	".tmpl":   "Go template",
+	".gohtml": "Go template",
+}
+
+func find(root string, exts map[string]parseFunc) []string {
 	var a []string
 	filepath.WalkDir(root, func(s string, d fs.DirEntry, e error) error {
 		if e != nil {
 			return e
 		}
-		if filepath.Ext(d.Name()) == ext {
+		if _, ok := exts[filepath.Ext(d.Name())]; ok && !d.IsDir() {
 			a = append(a, s)
 		}
 		return nil
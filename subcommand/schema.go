@@ -0,0 +1,61 @@
+package subcommand
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/library"
+)
+
+type Schema struct {
+	Files  []string `arg:"" optional:"" enum:"threats.json,controls.json,components.json,threatModel.json" help:"Files of the threat model directory to export the schema of, all of them by default."`
+	Output string   `short:"o" help:"Directory to write the schemas to, named like threats.schema.json. A single schema is written to the standard output by default."`
+}
+
+// Help shows the Schema subcommand help.
+func (*Schema) Help() string {
+	return "This will export the JSON Schemas of the files of the threat model directory, for\n    editors and other tools reading or writing them:\n    threatmodel/threats.json threatmodel/controls.json threatmodel/components.json\n    threatmodel/threatModel.json\n    \n    $ famed-annotated schema threats.json\n    $ famed-annotated schema --output schemas"
+}
+
+// Run writes the schemas.
+func (a *Schema) Run() error {
+	files := a.Files
+	if len(files) == 0 {
+		files = library.Files
+	}
+
+	if a.Output == "" {
+		if len(files) > 1 {
+			return eris.New("several schemas can only be written to a directory, set with --output")
+		}
+
+		schema, err := library.Schema(files[0])
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stdout.Write(schema); err != nil {
+			return eris.Wrap(err, "failed to write to the standard output")
+		}
+
+		return nil
+	}
+
+	if err := os.MkdirAll(a.Output, 0o755); err != nil { //nolint:gosec // schemas are meant to be shared
+		return eris.Wrapf(err, "failed to create %s", a.Output)
+	}
+	for _, file := range files {
+		schema, err := library.Schema(file)
+		if err != nil {
+			return err
+		}
+
+		filename := filepath.Join(a.Output, filepath.Base(library.SchemaName(file)))
+		if err := os.WriteFile(filename, schema, 0o644); err != nil { //nolint:gosec // schemas are meant to be shared
+			return eris.Wrapf(err, "failed to write %s", filename)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package subcommand
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+// timestamp returns the time the outputs are stamped with: the given flag value, else the SOURCE_DATE_EPOCH environment
+// variable of reproducible builds, else the current time. Values are either Unix timestamps or RFC 3339 dates.
+func timestamp(flag string) (time.Time, error) {
+	value := flag
+	if value == "" {
+		value = os.Getenv("SOURCE_DATE_EPOCH")
+	}
+	if value == "" {
+		return time.Now(), nil
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, eris.Wrapf(err, "invalid timestamp %s, expected a Unix timestamp or an RFC 3339 date", value)
+	}
+
+	return t.UTC(), nil
+}
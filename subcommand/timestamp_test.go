@@ -0,0 +1,36 @@
+package subcommand
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimestamp(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		env     string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "unix flag", flag: "1700000000", want: time.Unix(1700000000, 0).UTC()},
+		{name: "rfc 3339 flag", flag: "2024-01-02T03:04:05+01:00", want: time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC)},
+		{name: "source date epoch", env: "1", want: time.Unix(1, 0).UTC()},
+		{name: "flag over source date epoch", flag: "2", env: "1", want: time.Unix(2, 0).UTC()},
+		{name: "invalid", flag: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SOURCE_DATE_EPOCH", tt.env)
+
+			got, err := timestamp(tt.flag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("timestamp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) || got.Location() != tt.want.Location() {
+				t.Errorf("timestamp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package subcommand
+
+import (
+	"github.com/phuslu/log"
+	"github.com/rotisserie/eris"
+
+	"github.com/morphysm/famed-annotated/config"
+	"github.com/morphysm/famed-annotated/library"
+)
+
+type Validate struct{}
+
+// Help shows the Validate subcommand help.
+func (*Validate) Help() string {
+	return "This will check the files of the threat model directory, which may be edited by hand,\n    against their JSON Schema, as exported by famed-annotated schema. Each error is\n    reported with the JSON pointer of the value at fault, like\n    threatModel.json#/mitigations/0/source/line.\n    \n    When the files match their schema, it also checks that the entries of the library\n    files are indexed by their id, and that every component, threat and control\n    referenced in threatModel.json exists in the library files."
+}
+
+// Run validates the threat model directory and fails if it holds any error.
+func (a *Validate) Run() error {
+	cfg, err := config.LoadFile()
+	if err != nil {
+		return err
+	}
+
+	errs, err := library.Validate(cfg.ThreatModelDir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range errs {
+		log.Error().Str("file", e.File).Str("pointer", e.Pointer).Msg(e.Message)
+	}
+	if len(errs) > 0 {
+		return eris.Errorf("%s holds %d errors", cfg.ThreatModelDir, len(errs))
+	}
+
+	log.Info().Msgf("%s is valid", cfg.ThreatModelDir)
+
+	return nil
+}